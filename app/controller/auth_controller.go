@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoginRequest login credentials
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// RefreshTokenRequest refresh token to rotate or revoke
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Login login with email and password
+func (h *Handler) Login(c *fiber.Ctx) error {
+	request := LoginRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
+	}
+	if request.Email == "" || request.Password == "" {
+		return lib.ErrorBadRequest(c, "Required Email and Password")
+	}
+
+	user, err := h.Users.FindByEmail(lib.Context(c), request.Email)
+	if errors.Is(err, services.ErrNotFound) || (err == nil && !user.CheckPassword(request.Password)) {
+		return lib.ErrorUnauthorized(c, "Invalid Email or Password")
+	} else if err != nil {
+		return databaseError(c, err)
+	}
+
+	token, err := h.issueToken(c, user)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OK(c, token)
+}
+
+// RefreshToken rotate the refresh token
+func (h *Handler) RefreshToken(c *fiber.Ctx) error {
+	request := RefreshTokenRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
+	}
+
+	stored, err := h.revokeToken(c, request.RefreshToken)
+	if err == services.ErrInvalidToken {
+		return lib.ErrorInvalidToken(c)
+	} else if err != nil {
+		return databaseError(c, err)
+	}
+
+	user, err := h.Users.Find(lib.Context(c), stored.UserID)
+	if errors.Is(err, services.ErrNotFound) {
+		return lib.ErrorInvalidToken(c)
+	} else if err != nil {
+		return databaseError(c, err)
+	}
+
+	token, err := h.issueToken(c, user)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OK(c, token)
+}
+
+// Logout revoke the refresh token, the access token stay valid until it expires
+func (h *Handler) Logout(c *fiber.Ctx) error {
+	request := RefreshTokenRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
+	}
+
+	if _, err := h.revokeToken(c, request.RefreshToken); err == services.ErrInvalidToken {
+		return lib.ErrorInvalidToken(c)
+	} else if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OK(c)
+}
+
+// issueToken issue the token pair of the user and store its refresh token
+func (h *Handler) issueToken(c *fiber.Ctx, user *model.User) (*services.TokenPair, error) {
+	token, refresh, err := services.IssueToken(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.Tokens.Create(lib.Context(c), refresh); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// revokeToken verify the refresh token and revoke it, return the stored refresh token
+func (h *Handler) revokeToken(c *fiber.Ctx, token string) (*model.RefreshToken, error) {
+	claims, err := services.ParseToken(token, services.TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	return h.Tokens.Revoke(lib.Context(c), claims.ID)
+}
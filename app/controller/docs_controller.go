@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/razanlrahardjo/hacktiv8/docs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// DocsSpecPath path of the openapi document, loaded by the swagger ui
+const DocsSpecPath = "/docs/openapi.json"
+
+// docsSpec openapi document served with the configured server urls
+var docsSpec []byte
+
+// docsIndex swagger ui page, the assets are served from /docs
+const docsIndex = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>Master API</title>
+  <link rel="stylesheet" type="text/css" href="/docs/swagger-ui.css">
+  <link rel="icon" type="image/png" href="/docs/favicon-32x32.png" sizes="32x32">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="/docs/swagger-ui-bundle.js"></script>
+<script src="/docs/swagger-ui-standalone-preset.js"></script>
+<script>
+window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "` + DocsSpecPath + `",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    layout: "StandaloneLayout"
+  })
+}
+</script>
+</body>
+</html>
+`
+
+// ConfigureDocs fill the server urls of the openapi document from the configured endpoint,
+// the api paths are served under the endpoint and the paths with their own servers from the root,
+// the urls are relative so they resolve against the host serving the document
+func ConfigureDocs(endpoint string) {
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(docs.OpenAPI, &spec); err != nil {
+		panic(err)
+	}
+
+	spec["servers"] = []map[string]string{{"url": serverURL(endpoint)}}
+	paths, _ := spec["paths"].(map[string]interface{})
+	for _, item := range paths {
+		item, _ := item.(map[string]interface{})
+		if _, ok := item["servers"]; ok {
+			item["servers"] = []map[string]string{{"url": serverURL("/")}}
+		}
+	}
+
+	bte, err := json.Marshal(spec)
+	if err != nil {
+		panic(err)
+	}
+	docsSpec = bte
+}
+
+// serverURL relative server url of the base path
+func serverURL(basePath string) string {
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath == "" {
+		return "/"
+	}
+	return basePath
+}
+
+// DocsSpec openapi document of the api
+func DocsSpec(c *fiber.Ctx) error {
+	return c.Type("json").Send(docsSpec)
+}
+
+// DocsUI interactive swagger ui of the openapi document
+func DocsUI(c *fiber.Ctx) error {
+	return c.Type("html").SendString(docsIndex)
+}
+
+// DocsAssets swagger ui assets embedded in the binary, mounted on /docs,
+// the bundled index page loading the petstore example is skipped
+func DocsAssets() fiber.Handler {
+	return filesystem.New(filesystem.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return strings.HasSuffix(c.Path(), "/index.html") || strings.HasSuffix(c.Path(), "/swagger-initializer.js")
+		},
+		Root: http.FS(swaggerFiles.FS),
+	})
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// databaseError send the problem of the classified database error,
+// the duplicate message replace the detail of the unique violation,
+// the record changed concurrently after the If-Match check is a failed precondition
+func databaseError(c *fiber.Ctx, err error, duplicate ...string) error {
+	err = services.TranslateError(err)
+	if errors.Is(err, services.ErrSerializationFailure) && c.Get(fiber.HeaderIfMatch) != "" {
+		return lib.ErrorPreconditionFailed(c)
+	}
+	if errors.Is(err, services.ErrDuplicate) {
+		return lib.SendError(c, err, duplicate...)
+	}
+	return lib.SendError(c, err)
+}
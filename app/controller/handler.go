@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/razanlrahardjo/hacktiv8/app/middleware"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler todo, user, status, trash and auth controllers with their data access
+type Handler struct {
+	Todos  repository.TodoRepository
+	Users  repository.UserRepository
+	Status repository.StatusRepository
+	Trash  repository.TrashRepository
+	Tokens repository.TokenRepository
+}
+
+// NewHandler handler using the repositories
+func NewHandler(repositories repository.Repositories) *Handler {
+	return &Handler{
+		Todos:  repositories.Todos,
+		Users:  repositories.Users,
+		Status: repositories.Status,
+		Trash:  repositories.Trash,
+		Tokens: repositories.Tokens,
+	}
+}
+
+// paramID id path parameter, false when it isn't a number so the resource can't exist
+func paramID(c *fiber.Ctx) (int, bool) {
+	id, err := strconv.Atoi(c.Params("id"))
+	return id, err == nil
+}
+
+// isCaller check whether the id is the authenticated user of the request
+func isCaller(c *fiber.Ctx, id int) bool {
+	user := middleware.GetUser(c)
+	return nil != user && user.ID == id
+}
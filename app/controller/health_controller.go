@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Healthz liveness probe, 200 while the process can answer
+func Healthz(c *fiber.Ctx) error {
+	return c.JSON(services.Liveness())
+}
+
+// Readyz readiness probe, 503 when any check is down so the instance doesn't receive traffic
+func Readyz(c *fiber.Ctx) error {
+	health := services.Readiness(lib.Context(c))
+	status := fiber.StatusOK
+	if health.Status != services.HealthUp {
+		status = fiber.StatusServiceUnavailable
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(status).JSON(health)
+}
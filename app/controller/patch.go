@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Media types of the PATCH request body
+const (
+	MIMEMergePatch = "application/merge-patch+json" // RFC 7396 JSON Merge Patch
+	MIMEJSONPatch  = "application/json-patch+json"  // RFC 6902 JSON Patch
+)
+
+// applyPatch apply the merge patch or json patch of the request body to the json document of the record
+// and decode the patched document into the target, the problem is sent when the patch can't be applied
+func applyPatch(c *fiber.Ctx, record interface{}, target interface{}) (bool, error) {
+	document, err := json.Marshal(record)
+	if err != nil {
+		return false, lib.ErrorInternal(c, err)
+	}
+
+	var patched []byte
+	switch mediaType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0])); mediaType {
+	case MIMEMergePatch:
+		if patched, err = jsonpatch.MergePatch(document, c.Body()); err != nil {
+			return false, lib.ErrorBadRequest(c, "Invalid patch %s", err.Error())
+		}
+	case MIMEJSONPatch:
+		patch, err := jsonpatch.DecodePatch(c.Body())
+		if err != nil {
+			return false, lib.ErrorBadRequest(c, "Invalid patch %s", err.Error())
+		}
+		if patched, err = patch.Apply(document); err != nil {
+			// a failed test operation is a precondition of the client on the current record
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return false, lib.ErrorConflict(c, "Patch test failed")
+			}
+			return false, lib.SendProblem(c, lib.Problem{Status: 422, Detail: "Can't apply patch %s", Args: []interface{}{err.Error()}})
+		}
+	default:
+		return false, lib.SendProblem(c, lib.Problem{
+			Status: fiber.StatusUnsupportedMediaType,
+			Detail: "Unsupported media type %s",
+			Args:   []interface{}{mediaType},
+		})
+	}
+
+	if err := json.Unmarshal(patched, target); err != nil {
+		return false, lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
+	}
+	return true, nil
+}
@@ -2,162 +2,255 @@ package controller
 
 import (
 	"encoding/json"
-	"fmt"
-	"strings"
+	"errors"
+	"strconv"
 
 	"github.com/razanlrahardjo/hacktiv8/app/lib"
 	"github.com/razanlrahardjo/hacktiv8/app/model"
-	"github.com/razanlrahardjo/hacktiv8/app/services"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// PostStatus godoc
-// @Summary Create new status
-// @Description Create new status
-// @Param X-Status-ID header string false "Status ID"
-// @Param data body model.Status true "Status data"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.Status data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /status [post]
-// @Tags Status
-func PostStatus(c *fiber.Ctx) error {
+// PostStatus create new status
+func (h *Handler) PostStatus(c *fiber.Ctx) error {
 	status := model.Status{}
 	if err := c.BodyParser(&status); err != nil {
-		return lib.ErrorBadRequest(c, err.Error())
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
 	}
 
 	// check required / not null field status
 	validation := status.Validation("create")
 	if len(validation) != 0 {
-		return lib.ErrorBadRequest(c, validation)
+		return lib.ErrorValidation(c, validation)
+	}
+	if status.IsTerminal == nil {
+		terminal := false
+		status.IsTerminal = &terminal
 	}
 
-	db := services.DB
 	// Create Data Status
-	if tx := db.Create(&status); tx.Error != nil {
-		if strings.Contains(tx.Error.Error(), "duplicate") || strings.Contains(strings.ToLower(tx.Error.Error()), "unique") {
-			return lib.ErrorConflict(c, "Duplicate Status")
-		}
-		return lib.ErrorInternal(c, tx.Error.Error())
+	if err := h.Status.Create(lib.Context(c), &status); err != nil {
+		return databaseError(c, err, "Duplicate Status")
 	}
 
-	return lib.OK(c, status)
+	return lib.OKVersion(c, status.Version, status)
 }
 
-// GetStatus godoc
-// @Summary List of status features
-// @Description List of status features
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} []model.Status List of status features
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /status [get]
-// @Tags Status
-func GetStatus(c *fiber.Ctx) error {
-	db := services.DB
-
-	var status []model.Status
-	db.Model(&model.Status{}).Find(&status)
-
-	return lib.OK(c, status)
-}
+// GetStatus list of status features
+func (h *Handler) GetStatus(c *fiber.Ctx) error {
+	page, err := lib.GetCursorPagination(c)
+	if err != nil {
+		return lib.SendError(c, err)
+	}
 
-// GetStatusID godoc
-// @Summary Get an status feature by id
-// @Description Get an status feature by id
-// @Param id path string true "Status ID"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.Status data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /status/{id} [get]
-// @Tags Status
-func GetStatusID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	db := services.DB
+	status, err := h.Status.ListCursor(lib.Context(c), page)
+	if err != nil {
+		return databaseError(c, err)
+	}
 
-	status := model.Status{}
-	result := db.Model(&status).Where("id = ?", &id).First(&status)
-	if result.RowsAffected < 1 {
+	return lib.SendCursorPage(c, page, status)
+}
+
+// GetStatusID get an status feature by id
+func (h *Handler) GetStatusID(c *fiber.Ctx) error {
+	id, ok := paramID(c)
+	if !ok {
 		return lib.ErrorNotFound(c)
 	}
 
-	return lib.OK(c, status)
+	status, err := h.Status.Find(lib.Context(c), id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OKVersion(c, status.Version, status)
 }
 
-// PutStatus godoc
-// @Summary Update status feature by id
-// @Description Update status feature by id
-// @Param X-Status-ID header string false "Status ID"
-// @Param id path string true "Status ID"
-// @Param data body model.Status true "Status data"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.Status data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /status/{id} [put]
-// @Tags Status
-func PutStatus(c *fiber.Ctx) error {
-	db := services.DB
-	id := c.Params("id")
+// PutStatus replace status feature by id, the omitted fields are cleared
+func (h *Handler) PutStatus(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
 
-	status := model.Status{}
 	// check id if exist
-	result := db.Where("id = ?", id).First(&status)
-	if result.RowsAffected < 1 {
-		return lib.ErrorNotFound(c, fmt.Sprintf("%s %s", result.Error.Error(), id))
+	current, err := h.Status.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
 	}
-	if err := json.Unmarshal(c.Body(), &status); err != nil {
-		return lib.ErrorBadRequest(c, err.Error())
+	if !lib.IfMatch(c, current.Version) {
+		return lib.ErrorPreconditionFailed(c)
 	}
-	if tx := db.Updates(&status); tx.Error != nil {
-		return lib.ErrorConflict(c, tx.Error.Error())
+	status := model.Status{}
+	if err := json.Unmarshal(c.Body(), &status); err != nil {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
 	}
-	return lib.OK(c, status)
+	return h.replaceStatus(c, current, &status)
 }
 
-// DeleteStatus godoc
-// @Summary StatusDelete status feature by id
-// @Description StatusDelete status feature by id
-// @Param id path string true "Status ID"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} lib.Response
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /status/{id} [delete]
-// @Tags Status
-func DeleteStatus(c *fiber.Ctx) error {
-	id := c.Params("id")
-	db := services.DB
+// PatchStatus apply the json merge patch or json patch to status feature by id
+func (h *Handler) PatchStatus(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
 
+	// check id if exist
+	current, err := h.Status.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+	if !lib.IfMatch(c, current.Version) {
+		return lib.ErrorPreconditionFailed(c)
+	}
 	status := model.Status{}
-	result := db.Model(&status).Where("id = ?", &id).First(&status)
-	if result.RowsAffected < 1 {
+	if ok, err := applyPatch(c, current, &status); !ok {
+		return err
+	}
+	return h.replaceStatus(c, current, &status)
+}
+
+// replaceStatus validate and store the status replacing the current status
+func (h *Handler) replaceStatus(c *fiber.Ctx, current *model.Status, status *model.Status) error {
+	status.Base = current.Base
+	validation := status.Validation("replace")
+	if len(validation) != 0 {
+		return lib.ErrorValidation(c, validation)
+	}
+	if status.IsTerminal == nil {
+		terminal := false
+		status.IsTerminal = &terminal
+	}
+	if err := h.Status.Update(lib.Context(c), status); err != nil {
+		return databaseError(c, err, "Duplicate Status")
+	}
+	return lib.OKVersion(c, status.Version, status)
+}
+
+// DeleteStatus delete status feature by id
+func (h *Handler) DeleteStatus(c *fiber.Ctx) error {
+	id, ok := paramID(c)
+	if !ok {
 		return lib.ErrorNotFound(c)
 	}
 
-	db.Delete(&status)
+	// the version is only read to honor If-Match, the delete fail when it changed since
+	version := 0
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		status, err := h.Status.Find(lib.Context(c), id)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		if !lib.IfMatch(c, status.Version) {
+			return lib.ErrorPreconditionFailed(c)
+		}
+		version = status.Version
+	}
+
+	reassignTo := 0
+	if reassign := c.Query("reassign_to"); reassign != "" {
+		value, err := strconv.Atoi(reassign)
+		if err != nil || value == 0 {
+			return lib.ErrorBadRequest(c, repository.ErrInvalidReassign.Error())
+		}
+		reassignTo = value
+	}
+
+	err := h.Status.Delete(lib.Context(c), id, version, reassignTo)
+	var inUse *repository.StatusInUseError
+	if errors.As(err, &inUse) {
+		return lib.SendProblem(c, lib.Problem{Status: 409, Code: lib.CodeStatusInUse, Detail: "Status is used by %d todo", Args: []interface{}{inUse.Used}})
+	} else if errors.Is(err, repository.ErrInvalidReassign) {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
+	} else if err != nil {
+		return databaseError(c, err)
+	}
 
 	return lib.OK(c)
 }
+
+// GetStatusTransition list of the next status allowed from the status
+func (h *Handler) GetStatusTransition(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+
+	status, err := h.Status.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	allowed, err := h.Status.Allowed(ctx, status)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OK(c, allowed)
+}
+
+// StatusTransitionRequest next status allowed from the status
+type StatusTransitionRequest struct {
+	ToStatusIDs []int `json:"to_status_ids"`
+}
+
+// PutStatusTransition replace the next status allowed from the status
+func (h *Handler) PutStatusTransition(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+
+	status, err := h.Status.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	request := StatusTransitionRequest{}
+	if err := c.BodyParser(&request); err != nil {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
+	}
+	if len(request.ToStatusIDs) > 0 && status.IsTerminal != nil && *status.IsTerminal {
+		return lib.ErrorBadRequest(c, "Terminal Status Can't Have Transition")
+	}
+
+	toStatusIDs := uniqueInt(request.ToStatusIDs)
+	if len(toStatusIDs) > 0 {
+		found, err := h.Status.FindByIDs(ctx, toStatusIDs)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		if len(found) != len(toStatusIDs) {
+			return lib.ErrorBadRequest(c, "Status Not Found")
+		}
+	}
+
+	if err := h.Status.ReplaceTransitions(ctx, status.ID, toStatusIDs); err != nil {
+		return databaseError(c, err, "Duplicate Status")
+	}
+
+	allowed, err := h.Status.Allowed(ctx, status)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OK(c, allowed)
+}
+
+// uniqueInt remove the duplicate value keeping the order
+func uniqueInt(values []int) []int {
+	unique := []int{}
+	seen := map[int]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -2,166 +2,349 @@ package controller
 
 import (
 	"encoding/json"
-	"fmt"
-	"strings"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/razanlrahardjo/hacktiv8/app/lib"
 	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
 	"github.com/razanlrahardjo/hacktiv8/app/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// PostTodo godoc
-// @Summary Create new todo
-// @Description Create new todo
-// @Param X-Todo-ID header string false "Todo ID"
-// @Param data body model.Todo true "Todo data"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.Todo data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /todos [post]
-// @Tags Todo
-func PostTodo(c *fiber.Ctx) error {
+// todoSortable sort fields accepted on todo list
+var todoSortable = map[string]string{
+	"id":         "id",
+	"title":      "title",
+	"due_date":   "due_date",
+	"status":     "status_id",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// PostTodo create new todo
+func (h *Handler) PostTodo(c *fiber.Ctx) error {
 	todo := model.Todo{}
 	if err := c.BodyParser(&todo); err != nil {
-		return lib.ErrorBadRequest(c, err.Error())
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
 	}
 
 	// check required / not null field todo
 	validation := todo.Validation("create")
 	if len(validation) != 0 {
-		return lib.ErrorBadRequest(c, validation)
+		return lib.ErrorValidation(c, validation)
 	}
 
-	db := services.DB
+	ctx := lib.Context(c)
+	if ok, err := h.resolveTodoStatus(c, &todo); !ok {
+		return err
+	}
+	if ok, err := h.resolveTodoAssignees(c, &todo); !ok {
+		return err
+	}
 	// Create Data Todo
-	if tx := db.Create(&todo); tx.Error != nil {
-		if strings.Contains(tx.Error.Error(), "duplicate") || strings.Contains(strings.ToLower(tx.Error.Error()), "unique") {
-			return lib.ErrorConflict(c, "Duplicate Todo")
-		}
-		return lib.ErrorInternal(c, tx.Error.Error())
+	if err := h.Todos.Create(ctx, &todo); err != nil {
+		return databaseError(c, err, "Duplicate Todo")
 	}
+	todo.AssigneeIDs = nil
 
-	return lib.OK(c, todo)
+	return lib.OKETag(c, todoETag(&todo), todo)
 }
 
-// GetTodo godoc
-// @Summary List of todo features
-// @Description List of todo features
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} []model.Todo List of todo features
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /todos [get]
-// @Tags Todo
-func GetTodo(c *fiber.Ctx) error {
-	db := services.DB
-
-	var todos []model.Todo
-	db.Model(&model.Todo{}).Find(&todos)
-
-	return lib.OK(c, todos)
+// GetTodo list of todo features
+func (h *Handler) GetTodo(c *fiber.Ctx) error {
+	return h.listTodo(c, repository.TodoFilter{})
 }
 
-// GetTodoID godoc
-// @Summary Get an todo feature by id
-// @Description Get an todo feature by id
-// @Param id path string true "Todo ID"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.Todo data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /todos/{id} [get]
-// @Tags Todo
-func GetTodoID(c *fiber.Ctx) error {
-	id := c.Params("id")
-	db := services.DB
+// listTodo list the todo with the filter and pagination from query string added to the filter,
+// the assignee_id query is rejected when the filter already has the assignee
+func (h *Handler) listTodo(c *fiber.Ctx, filter repository.TodoFilter) error {
+	ctx := lib.Context(c)
 
-	todo := model.Todo{}
-	result := db.Model(&todo).Where("id = ?", &id).First(&todo)
-	if result.RowsAffected < 1 {
+	if status := c.Query("status"); status != "" {
+		if statusID, err := strconv.Atoi(status); err == nil {
+			filter.StatusID = &statusID
+		} else {
+			filter.StatusText = &status
+		}
+	}
+	if assignee := c.Query("assignee_id"); assignee != "" {
+		// the assignee of the per-user list is the user of the path
+		assigneeID, err := strconv.Atoi(assignee)
+		if err != nil || filter.AssigneeID != nil {
+			return lib.ErrorBadRequest(c, "Invalid assignee_id %s", assignee)
+		}
+		filter.AssigneeID = &assigneeID
+	}
+	for param, value := range map[string]**string{
+		"due_date_from": &filter.DueDateFrom,
+		"due_date_to":   &filter.DueDateTo,
+	} {
+		if date := c.Query(param); date != "" {
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				return lib.ErrorBadRequest(c, "Invalid %s %s", param, date)
+			}
+			*value = &date
+		}
+	}
+
+	if lib.IsCursorPagination(c) {
+		page, err := lib.GetCursorPagination(c)
+		if err != nil {
+			return lib.SendError(c, err)
+		}
+
+		todos, err := h.Todos.ListCursor(ctx, filter, page)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		return lib.SendCursorPage(c, page, todos)
+	}
+
+	page, err := lib.GetPagination(c, todoSortable)
+	if err != nil {
+		return lib.SendError(c, err)
+	}
+
+	todos, total, err := h.Todos.List(ctx, filter, page)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.SendPage(c, page, total, todos)
+}
+
+// GetTodoID get an todo feature by id
+func (h *Handler) GetTodoID(c *fiber.Ctx) error {
+	id, ok := paramID(c)
+	if !ok {
 		return lib.ErrorNotFound(c)
 	}
 
-	return lib.OK(c, todo)
+	todo, err := h.Todos.Find(lib.Context(c), id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OKETag(c, todoETag(todo), todo)
 }
 
-// PutTodo godoc
-// @Summary Update todo feature by id
-// @Description Update todo feature by id
-// @Param X-Todo-ID header string false "Todo ID"
-// @Param id path string true "Todo ID"
-// @Param data body model.Todo true "Todo data"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.Todo data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /todos/{id} [put]
-// @Tags Todo
-func PutTodo(c *fiber.Ctx) error {
-	db := services.DB
-	id := c.Params("id")
+// PutTodo replace todo feature by id, the omitted fields are cleared
+func (h *Handler) PutTodo(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
 
-	todo := model.Todo{}
 	// check id if exist
-	result := db.Where("id = ?", id).First(&todo)
-	if result.RowsAffected < 1 {
-		return lib.ErrorNotFound(c, fmt.Sprintf("%s %s", result.Error.Error(), id))
+	current, err := h.Todos.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
 	}
-	validation := todo.Validation("update")
-	if len(validation) != 0 {
-		return lib.ErrorBadRequest(c, validation)
+	if !lib.IfMatchETag(c, todoETag(current)) {
+		return lib.ErrorPreconditionFailed(c)
 	}
+	todo := model.Todo{}
 	if err := json.Unmarshal(c.Body(), &todo); err != nil {
-		return lib.ErrorBadRequest(c, err.Error())
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
 	}
-	if tx := db.Updates(&todo); tx.Error != nil {
-		return lib.ErrorConflict(c, tx.Error.Error())
-	}
-	return lib.OK(c, todo)
+	return h.replaceTodo(c, current, &todo)
 }
 
-// DeleteTodo godoc
-// @Summary TodoDelete todo feature by id
-// @Description TodoDelete todo feature by id
-// @Param id path string true "Todo ID"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} lib.Response
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /todos/{id} [delete]
-// @Tags Todo
-func DeleteTodo(c *fiber.Ctx) error {
-	id := c.Params("id")
-	db := services.DB
+// PatchTodo apply the json merge patch or json patch to todo feature by id
+func (h *Handler) PatchTodo(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
 
+	// check id if exist
+	current, err := h.Todos.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+	if !lib.IfMatchETag(c, todoETag(current)) {
+		return lib.ErrorPreconditionFailed(c)
+	}
+	// the patched document is the todo as returned with the assignee ids,
+	// the assignees in the trash too so they are kept
+	document := *current
+	if document.AssigneeIDs, err = h.Todos.AssigneeIDs(ctx, id); err != nil {
+		return databaseError(c, err)
+	}
 	todo := model.Todo{}
-	result := db.Model(&todo).Where("id = ?", &id).First(&todo)
-	if result.RowsAffected < 1 {
+	if ok, err := applyPatch(c, document, &todo); !ok {
+		return err
+	}
+	return h.replaceTodo(c, current, &todo)
+}
+
+// replaceTodo validate and store the todo replacing the current todo,
+// the status workflow is checked when the status changed
+func (h *Handler) replaceTodo(c *fiber.Ctx, current *model.Todo, todo *model.Todo) error {
+	ctx := lib.Context(c)
+	todo.Base = current.Base
+	validation := todo.Validation("replace")
+	if len(validation) != 0 {
+		return lib.ErrorValidation(c, validation)
+	}
+	if ok, err := h.resolveTodoStatus(c, todo); !ok {
+		return err
+	}
+	// check the status workflow when the status changed
+	if current.Status != nil && *todo.StatusID != current.Status.ID {
+		allowed, err := h.Status.Allowed(ctx, current.Status)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		permitted := false
+		for _, next := range allowed {
+			permitted = permitted || next.ID == *todo.StatusID
+		}
+		if !permitted {
+			return lib.SendProblem(c, lib.Problem{
+				Status: 422,
+				Code:   lib.CodeInvalidTransition,
+				Detail: "Can't Change Status From %s To %s",
+				Args:   []interface{}{statusText(current.Status), statusText(todo.Status)},
+				Data:   allowed,
+			})
+		}
+	}
+	if ok, err := h.resolveTodoAssignees(c, todo); !ok {
+		return err
+	}
+	if err := h.Todos.Update(ctx, todo); err != nil {
+		return databaseError(c, err, "Duplicate Todo")
+	}
+	todo.AssigneeIDs = nil
+	return lib.OKETag(c, todoETag(todo), todo)
+}
+
+// DeleteTodo delete todo feature by id
+func (h *Handler) DeleteTodo(c *fiber.Ctx) error {
+	id, ok := paramID(c)
+	if !ok {
 		return lib.ErrorNotFound(c)
 	}
 
-	db.Delete(&todo)
+	// the version is only read to honor If-Match, the delete fail when it changed since
+	version := 0
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		todo, err := h.Todos.Find(lib.Context(c), id)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		if !lib.IfMatchETag(c, todoETag(todo)) {
+			return lib.ErrorPreconditionFailed(c)
+		}
+		version = todo.Version
+	}
+
+	if err := h.Todos.Delete(lib.Context(c), id, version); err != nil {
+		return databaseError(c, err)
+	}
 
 	return lib.OK(c)
 }
+
+// resolveTodoStatus set the status id from the status text, which take precedence over the id,
+// and make sure the status exist, the problem is sent when it returns false
+func (h *Handler) resolveTodoStatus(c *fiber.Ctx, todo *model.Todo) (bool, error) {
+	if todo.StatusID == nil && todo.StatusText == nil {
+		return true, nil
+	}
+
+	ctx := lib.Context(c)
+	var status *model.Status
+	var err error
+	if todo.StatusText != nil {
+		status, err = h.Status.FindByText(ctx, *todo.StatusText)
+	} else {
+		status, err = h.Status.Find(ctx, *todo.StatusID)
+	}
+	if errors.Is(err, services.ErrNotFound) {
+		return false, lib.ErrorBadRequest(c, "Status Not Found")
+	}
+	if err != nil {
+		return false, databaseError(c, err)
+	}
+
+	todo.StatusID = &status.ID
+	todo.StatusText = nil
+	todo.Status = status
+	return true, nil
+}
+
+// resolveTodoAssignees load the assignee users from the assignee ids and make sure all of them exist,
+// the users in the trash already assigned to the stored todo are accepted, the repository keep them,
+// the problem is sent when it returns false
+func (h *Handler) resolveTodoAssignees(c *fiber.Ctx, todo *model.Todo) (bool, error) {
+	if todo.AssigneeIDs == nil {
+		return true, nil
+	}
+
+	ctx := lib.Context(c)
+	users, err := h.Users.FindByIDs(ctx, todo.AssigneeIDs)
+	if err != nil {
+		return false, databaseError(c, err)
+	}
+	var assigned []int
+	for _, id := range todo.AssigneeIDs {
+		found := false
+		for _, user := range users {
+			found = found || user.ID == id
+		}
+		if !found && todo.ID != 0 && assigned == nil {
+			if assigned, err = h.Todos.AssigneeIDs(ctx, todo.ID); err != nil {
+				return false, databaseError(c, err)
+			}
+		}
+		if !found && !containsInt(assigned, id) {
+			return false, lib.ErrorBadRequest(c, "Assignee Not Found %d", id)
+		}
+	}
+
+	todo.Assignees = users
+	return true, nil
+}
+
+// statusText status text or empty string
+func statusText(status *model.Status) string {
+	if status == nil || status.StatusText == nil {
+		return ""
+	}
+	return *status.StatusText
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// todoETag entity tag of the todo version and the versions of its status and assignees,
+// it changes when the embedded status or assignee change too
+func todoETag(todo *model.Todo) string {
+	embedded := []int{}
+	if todo.Status != nil {
+		embedded = append(embedded, todo.Status.ID, todo.Status.Version)
+	}
+	// the preloaded assignees aren't ordered
+	assignees := append([]model.User{}, todo.Assignees...)
+	sort.Slice(assignees, func(i, j int) bool {
+		return assignees[i].ID < assignees[j].ID
+	})
+	for _, user := range assignees {
+		embedded = append(embedded, user.ID, user.Version)
+	}
+	return lib.ETag(todo.Version, embedded...)
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTrash list of deleted data
+func (h *Handler) GetTrash(c *fiber.Ctx) error {
+	page, err := lib.GetCursorPagination(c)
+	if err != nil {
+		return lib.SendError(c, err)
+	}
+
+	items, err := h.Trash.ListCursor(lib.Context(c), c.Params("resource"), page)
+	if errors.Is(err, services.ErrNotFound) {
+		return lib.ErrorNotFound(c)
+	} else if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.SendCursorPage(c, page, items)
+}
+
+// DeleteTrash permanently delete data from the trash
+func (h *Handler) DeleteTrash(c *fiber.Ctx) error {
+	return h.purge(c, c.Params("resource"))
+}
+
+// DeleteTrashUser permanently delete the authenticated user from the trash
+func (h *Handler) DeleteTrashUser(c *fiber.Ctx) error {
+	return h.purge(c, "users")
+}
+
+// purge permanently delete the resource data by id from the trash, only the owner purge the user account
+func (h *Handler) purge(c *fiber.Ctx, resource string) error {
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+	if resource == "users" && !isCaller(c, id) {
+		return lib.ErrorForbidden(c)
+	}
+
+	err := h.Trash.Purge(lib.Context(c), resource, id)
+	if err == services.ErrStatusInUse {
+		return lib.SendProblem(c, lib.Problem{Status: 409, Code: lib.CodeStatusInUse, Detail: err.Error()})
+	} else if errors.Is(err, services.ErrNotFound) {
+		return lib.ErrorNotFound(c)
+	} else if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.OK(c)
+}
+
+// restore undo the soft delete of the resource data by id and send the restored data,
+// only the owner restore the user account
+func (h *Handler) restore(c *fiber.Ctx, resource string, duplicate string) error {
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+	if resource == "users" && !isCaller(c, id) {
+		return lib.ErrorForbidden(c)
+	}
+
+	value, err := h.Trash.Restore(lib.Context(c), resource, id)
+	if err == repository.ErrTrashedDependency {
+		return lib.ErrorConflict(c, err.Error())
+	} else if errors.Is(err, services.ErrNotFound) {
+		return lib.ErrorNotFound(c)
+	} else if err != nil {
+		return databaseError(c, err, duplicate)
+	}
+
+	return lib.OK(c, value)
+}
+
+// RestoreTodo restore deleted todo by id, the status and assignees must be restored first
+func (h *Handler) RestoreTodo(c *fiber.Ctx) error {
+	return h.restore(c, "todos", "Duplicate Todo")
+}
+
+// RestoreUser restore the deleted authenticated user by id
+func (h *Handler) RestoreUser(c *fiber.Ctx) error {
+	return h.restore(c, "users", "Duplicate User")
+}
+
+// RestoreStatus restore deleted status by id
+func (h *Handler) RestoreStatus(c *fiber.Ctx) error {
+	return h.restore(c, "status", "Duplicate Status")
+}
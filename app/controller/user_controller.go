@@ -2,136 +2,235 @@ package controller
 
 import (
 	"encoding/json"
-	"fmt"
 	"strings"
 
 	"github.com/razanlrahardjo/hacktiv8/app/lib"
 	"github.com/razanlrahardjo/hacktiv8/app/model"
-	"github.com/razanlrahardjo/hacktiv8/app/services"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// PostUser godoc
-// @Summary Create new user
-// @Description Create new user
-// @Param X-User-ID header string false "User ID"
-// @Param data body model.User true "User data"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.User data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /users [post]
-// @Tags User
-func PostUser(c *fiber.Ctx) error {
+// PostUser create new user
+func (h *Handler) PostUser(c *fiber.Ctx) error {
 	user := model.User{}
 	if err := c.BodyParser(&user); err != nil {
-		return lib.ErrorBadRequest(c, err.Error())
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
 	}
 
 	// check required / not null field user
 	validation := user.Validation("create")
 	if len(validation) != 0 {
-		return lib.ErrorBadRequest(c, validation)
+		return lib.ErrorValidation(c, validation)
 	}
 
-	db := services.DB
 	// Create Data User
-	if tx := db.Create(&user); tx.Error != nil {
-		if strings.Contains(tx.Error.Error(), "duplicate") || strings.Contains(strings.ToLower(tx.Error.Error()), "unique") {
-			return lib.ErrorConflict(c, "Duplicate User")
+	if err := h.Users.Create(lib.Context(c), &user); err != nil {
+		return databaseError(c, err, "Duplicate User")
+	}
+
+	return lib.OKVersion(c, user.Version, user)
+}
+
+// GetUser list of user features
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	page, err := lib.GetCursorPagination(c)
+	if err != nil {
+		return lib.SendError(c, err)
+	}
+
+	filter := repository.UserFilter{}
+	if name := c.Query("name"); name != "" {
+		filter.Name = &name
+	}
+	if prefix := c.Query("name_prefix"); prefix != "" {
+		filter.NamePrefix = &prefix
+	}
+
+	users, err := h.Users.ListCursor(lib.Context(c), filter, page)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return lib.SendCursorPage(c, page, users)
+}
+
+// UserDetail user with the embedded resources
+type UserDetail struct {
+	*model.User
+	Todos *[]model.Todo         `json:"todos,omitempty"` // assigned todo ordered by due date, the first page of /users/{id}/todos
+	Stats *repository.UserStats `json:"stats,omitempty"` // stats of the assigned todo
+}
+
+// userEmbeds resources which can be embedded in the user
+var userEmbeds = map[string]bool{
+	"todos": true,
+	"stats": true,
+}
+
+// GetUserID get an user feature by id
+func (h *Handler) GetUserID(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+
+	embed := map[string]bool{}
+	for _, name := range strings.Split(c.Query("embed"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
 		}
-		return lib.ErrorInternal(c, tx.Error.Error())
+		if !userEmbeds[name] {
+			return lib.ErrorBadRequest(c, "Invalid embed %s", name)
+		}
+		embed[name] = true
+	}
+
+	user, err := h.Users.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
 	}
 
-	return lib.OK(c, user)
+	detail := UserDetail{User: user}
+	if len(embed) == 0 {
+		return lib.OKVersion(c, user.Version, detail)
+	}
+	if embed["todos"] {
+		page := lib.Pagination{Page: 1, Limit: lib.MaxPageLimit, Sort: []string{"due_date asc"}}
+		todos, _, err := h.Todos.List(ctx, repository.TodoFilter{AssigneeID: &user.ID}, page)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		detail.Todos = &todos
+	}
+	if embed["stats"] {
+		stats, err := h.Users.Stats(ctx, user.ID)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		detail.Stats = stats
+	}
+
+	// the embedded stats change with the time, the entity tag is weak of the representation
+	return lib.OKETag(c, lib.WeakETag(detail), detail)
 }
 
-// GetUser godoc
-// @Summary List of user features
-// @Description List of user features
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} []model.User List of user features
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /users [get]
-// @Tags User
-func GetUser(c *fiber.Ctx) error {
-	db := services.DB
-
-	var users []model.User
-	db.Model(&model.User{}).Find(&users)
-
-	return lib.OK(c, users)
+// GetUserTodo list of todo assigned to the user
+func (h *Handler) GetUserTodo(c *fiber.Ctx) error {
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+
+	user, err := h.Users.Find(lib.Context(c), id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+
+	return h.listTodo(c, repository.TodoFilter{AssigneeID: &user.ID})
 }
 
-// PutUser godoc
-// @Summary Update user feature by id
-// @Description Update user feature by id
-// @Param X-User-ID header string false "User ID"
-// @Param id path string true "User ID"
-// @Param data body model.User true "User data"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} model.User data
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /users/{id} [put]
-// @Tags User
-func PutUser(c *fiber.Ctx) error {
-	db := services.DB
-	id := c.Params("id")
+// PutUser replace the authenticated user by id, the omitted fields are cleared
+func (h *Handler) PutUser(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+	if !isCaller(c, id) {
+		return lib.ErrorForbidden(c)
+	}
 
-	user := model.User{}
 	// check id if exist
-	result := db.Where("id = ?", id).First(&user)
-	if result.RowsAffected < 1 {
-		return lib.ErrorNotFound(c, fmt.Sprintf("%s %s", result.Error.Error(), id))
+	current, err := h.Users.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
 	}
-	if err := json.Unmarshal(c.Body(), &user); err != nil {
-		return lib.ErrorBadRequest(c, err.Error())
+	if !lib.IfMatch(c, current.Version) {
+		return lib.ErrorPreconditionFailed(c)
 	}
-	if tx := db.Updates(&user); tx.Error != nil {
-		return lib.ErrorConflict(c, tx.Error.Error())
+	user := model.User{}
+	if err := json.Unmarshal(c.Body(), &user); err != nil {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
 	}
-	return lib.OK(c, user)
+	return h.replaceUser(c, current, &user)
 }
 
-// DeleteUser godoc
-// @Summary UserDelete user feature by id
-// @Description UserDelete user feature by id
-// @Param id path string true "User ID"
-// @Accept  application/json
-// @Produce application/json
-// @Success 200 {object} lib.Response
-// @Failure 400 {object} lib.Response
-// @Failure 404 {object} lib.Response
-// @Failure 409 {object} lib.Response
-// @Failure 500 {object} lib.Response
-// @Failure default {object} lib.Response
-// @Router /users/{id} [delete]
-// @Tags User
-func DeleteUser(c *fiber.Ctx) error {
-	id := c.Params("id")
-	db := services.DB
+// PatchUser apply the json merge patch or json patch to the authenticated user by id
+func (h *Handler) PatchUser(c *fiber.Ctx) error {
+	ctx := lib.Context(c)
+	id, ok := paramID(c)
+	if !ok {
+		return lib.ErrorNotFound(c)
+	}
+	if !isCaller(c, id) {
+		return lib.ErrorForbidden(c)
+	}
 
+	// check id if exist
+	current, err := h.Users.Find(ctx, id)
+	if err != nil {
+		return databaseError(c, err)
+	}
+	if !lib.IfMatch(c, current.Version) {
+		return lib.ErrorPreconditionFailed(c)
+	}
 	user := model.User{}
-	result := db.Model(&user).Where("id = ?", &id).First(&user)
-	if result.RowsAffected < 1 {
+	if ok, err := applyPatch(c, current, &user); !ok {
+		return err
+	}
+	return h.replaceUser(c, current, &user)
+}
+
+// replaceUser validate and store the user replacing the current user,
+// the new password is only accepted with the current password
+func (h *Handler) replaceUser(c *fiber.Ctx, current *model.User, user *model.User) error {
+	user.Base = current.Base
+	// the password is write only, the password hash is kept unless a new password is given
+	user.PasswordHash = current.PasswordHash
+	validation := user.Validation("replace")
+	if user.Password != nil && user.CurrentPassword == nil {
+		validation = append(validation, lib.NewFieldError("current_password", lib.CodeRequired, "Required %s", "Current Password"))
+	} else if user.Password != nil && !current.CheckPassword(*user.CurrentPassword) {
+		validation = append(validation, lib.NewFieldError("current_password", lib.CodeInvalidValue, "Invalid Current Password"))
+	}
+	if len(validation) != 0 {
+		return lib.ErrorValidation(c, validation)
+	}
+	if err := h.Users.Update(lib.Context(c), user); err != nil {
+		return databaseError(c, err, "Duplicate User")
+	}
+	return lib.OKVersion(c, user.Version, user)
+}
+
+// DeleteUser delete the authenticated user by id
+func (h *Handler) DeleteUser(c *fiber.Ctx) error {
+	id, ok := paramID(c)
+	if !ok {
 		return lib.ErrorNotFound(c)
 	}
+	if !isCaller(c, id) {
+		return lib.ErrorForbidden(c)
+	}
 
-	db.Delete(&user)
+	// the version is only read to honor If-Match, the delete fail when it changed since
+	version := 0
+	if c.Get(fiber.HeaderIfMatch) != "" {
+		user, err := h.Users.Find(lib.Context(c), id)
+		if err != nil {
+			return databaseError(c, err)
+		}
+		if !lib.IfMatch(c, user.Version) {
+			return lib.ErrorPreconditionFailed(c)
+		}
+		version = user.Version
+	}
+
+	if err := h.Users.Delete(lib.Context(c), id, version); err != nil {
+		return databaseError(c, err)
+	}
 
 	return lib.OK(c)
 }
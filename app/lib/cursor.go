@@ -0,0 +1,197 @@
+package lib
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+	"github.com/valyala/fasthttp"
+)
+
+// ErrInvalidCursor cursor can not be decoded or has been tampered
+var ErrInvalidCursor = NewRequestError("Invalid cursor")
+
+// ErrCursorSecret CURSOR_SECRET is not configured
+var ErrCursorSecret = errors.New("CURSOR_SECRET is not configured")
+
+// CursorKeyer item which can be used as cursor position
+type CursorKeyer interface {
+	CursorKey() (time.Time, int)
+}
+
+// Cursor position in the (updated_at, id) ordering
+type Cursor struct {
+	UpdatedAt time.Time `json:"u"`
+	ID        int       `json:"i"`
+	Backward  bool      `json:"b,omitempty"` // walk to the previous items
+}
+
+// CursorPagination cursor page request parsed from query string
+type CursorPagination struct {
+	Cursor *Cursor // nil on the first page
+	Limit  int     // items per page
+}
+
+// CursorPage cursor paginated response envelope
+type CursorPage struct {
+	Items      interface{} `json:"items"`       // list of items
+	Limit      int         `json:"limit"`       // items per page
+	NextCursor *string     `json:"next_cursor"` // cursor of the next page, null on the last page
+	PrevCursor *string     `json:"prev_cursor"` // cursor of the previous page, null on the first page
+}
+
+// CheckCursorSecret check the cursor signing key is configured, cursors must stay valid across processes
+func CheckCursorSecret() error {
+	if len(cursorSecret()) == 0 {
+		return ErrCursorSecret
+	}
+	return nil
+}
+
+func cursorSecret() []byte {
+	return []byte(viper.GetString("CURSOR_SECRET"))
+}
+
+func cursorSign(payload string) string {
+	mac := hmac.New(sha256.New, cursorSecret())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// EncodeCursor encode cursor to opaque signed string
+func EncodeCursor(cursor Cursor) string {
+	bte, _ := json.Marshal(cursor)
+	payload := base64.RawURLEncoding.EncodeToString(bte)
+	return payload + "." + cursorSign(payload)
+}
+
+// DecodeCursor decode and verify opaque signed string, no cursor is valid without the signing key
+func DecodeCursor(value string) (*Cursor, error) {
+	parts := strings.Split(value, ".")
+	if len(cursorSecret()) == 0 || len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(cursorSign(parts[0]))) {
+		return nil, ErrInvalidCursor
+	}
+
+	bte, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	cursor := Cursor{}
+	if err := json.Unmarshal(bte, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// IsCursorPagination check whether the client ask for cursor pagination
+func IsCursorPagination(c *fiber.Ctx) bool {
+	return c.Context().QueryArgs().Has("cursor")
+}
+
+// GetCursorPagination parse cursor and limit query parameter
+func GetCursorPagination(c *fiber.Ctx) (CursorPagination, error) {
+	p := CursorPagination{
+		Limit: DefaultPageLimit,
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > MaxPageLimit {
+			return p, NewRequestError("Invalid limit %s", limit)
+		}
+		p.Limit = value
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			return p, err
+		}
+		p.Cursor = cursor
+	}
+
+	return p, nil
+}
+
+// SendCursorPage send http 200 response with cursor paginated items,
+// items must be a slice of CursorKeyer fetched with limit + 1 rows
+func SendCursorPage(c *fiber.Ctx, p CursorPagination, items interface{}) error {
+	list := reflect.ValueOf(items)
+	if list.Kind() == reflect.Ptr {
+		list = list.Elem()
+	}
+
+	hasMore := list.Len() > p.Limit
+	if hasMore {
+		list = list.Slice(0, p.Limit)
+	}
+	backward := nil != p.Cursor && p.Cursor.Backward
+	if backward {
+		swap := reflect.Swapper(list.Interface())
+		for i, j := 0, list.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	var next, prev *Cursor
+	if list.Len() > 0 {
+		firstAt, firstID := list.Index(0).Interface().(CursorKeyer).CursorKey()
+		lastAt, lastID := list.Index(list.Len() - 1).Interface().(CursorKeyer).CursorKey()
+		if hasMore || backward {
+			next = &Cursor{UpdatedAt: lastAt, ID: lastID}
+		}
+		if (hasMore && backward) || (!backward && nil != p.Cursor) {
+			prev = &Cursor{UpdatedAt: firstAt, ID: firstID, Backward: true}
+		}
+	} else if nil != p.Cursor {
+		// nothing beyond the cursor, allow to walk back from the same position
+		turn := *p.Cursor
+		turn.Backward = !turn.Backward
+		if turn.Backward {
+			prev = &turn
+		} else {
+			next = &turn
+		}
+	}
+
+	page := CursorPage{
+		Items: list.Interface(),
+		Limit: p.Limit,
+	}
+	links := []string{}
+	if nil != next {
+		value := EncodeCursor(*next)
+		page.NextCursor = &value
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, cursorURL(c, value)))
+	}
+	if nil != prev {
+		value := EncodeCursor(*prev)
+		page.PrevCursor = &value
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, cursorURL(c, value)))
+	}
+	if len(links) > 0 {
+		c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+
+	return OK(c, page)
+}
+
+// cursorURL current request url with the cursor query replaced
+func cursorURL(c *fiber.Ctx, cursor string) string {
+	args := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(args)
+	c.Context().QueryArgs().CopyTo(args)
+	args.Set("cursor", cursor)
+
+	return c.BaseURL() + c.Path() + "?" + args.String()
+}
@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ETag strong entity tag of the record version, the versions of the embedded records are hashed after it
+func ETag(version int, embedded ...int) string {
+	if len(embedded) == 0 {
+		return fmt.Sprintf(`"%d"`, version)
+	}
+	hash := fnv.New32a()
+	for _, v := range embedded {
+		fmt.Fprintf(hash, "%d,", v)
+	}
+	return fmt.Sprintf(`"%d-%x"`, version, hash.Sum32())
+}
+
+// WeakETag weak entity tag of the json representation, for the data which isn't versioned
+func WeakETag(result interface{}) string {
+	bte, _ := json.Marshal(result)
+	hash := fnv.New64a()
+	hash.Write(bte)
+	return fmt.Sprintf(`W/"%x"`, hash.Sum64())
+}
+
+// IfMatch whether the If-Match header is absent or list the entity tag of the version, compared strongly
+func IfMatch(c *fiber.Ctx, version int) bool {
+	return IfMatchETag(c, ETag(version))
+}
+
+// IfMatchETag whether the If-Match header is absent or list the entity tag, compared strongly
+func IfMatchETag(c *fiber.Ctx, etag string) bool {
+	header := c.Get(fiber.HeaderIfMatch)
+	return header == "" || matchETag(header, etag, false)
+}
+
+// OKVersion send http 200 response of the record with the entity tag of its version,
+// or http 304 not modified to the read whose If-None-Match header list it
+func OKVersion(c *fiber.Ctx, version int, result interface{}) error {
+	return OKETag(c, ETag(version), result)
+}
+
+// OKETag send http 200 response with the entity tag,
+// or http 304 not modified to the read whose If-None-Match header list it
+func OKETag(c *fiber.Ctx, etag string, result interface{}) error {
+	c.Set(fiber.HeaderETag, etag)
+	if header := c.Get(fiber.HeaderIfNoneMatch); header != "" && (c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead) {
+		if matchETag(header, strings.TrimPrefix(etag, "W/"), true) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+	return OK(c, result)
+}
+
+// matchETag whether the header is "*" or list the entity tag, the weak comparison ignore the W/ prefix
+func matchETag(header string, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// messages translation catalog by language, keyed by the english message,
+// message with fmt verbs is looked up before formatting, ex: T(c, "Invalid page %s", page)
+var messages = map[string]map[string]string{
+	"id": {
+		"success":               "berhasil",
+		"Bad request":           "Permintaan tidak valid",
+		"Unauthorized":          "Tidak terautentikasi",
+		"Not found":             "Tidak ditemukan",
+		"Conflict":              "Konflik",
+		"Unprocessable entity":  "Data tidak dapat diproses",
+		"Internal server error": "Terjadi kesalahan pada server",
+		"Bad Request":           "Permintaan Tidak Valid",
+		"Forbidden":             "Akses Ditolak",
+		"Not Found":             "Tidak Ditemukan",
+		"Precondition Failed":   "Prasyarat Gagal",
+		"Unprocessable Entity":  "Data Tidak Dapat Diproses",
+		"Internal Server Error": "Kesalahan Server",
+		"Service Unavailable":   "Layanan Tidak Tersedia",
+
+		"Title":            "Judul",
+		"Description":      "Deskripsi",
+		"Due Date":         "Tenggat waktu",
+		"Status":           "Status",
+		"Status ID":        "Status",
+		"Status Text":      "Teks status",
+		"Assignee IDs":     "Penanggung jawab",
+		"Name":             "Nama",
+		"Email":            "Email",
+		"Password":         "Kata sandi",
+		"Current Password": "Kata sandi saat ini",
+		"Color":            "Warna",
+
+		"Required Email and Password":               "Email dan kata sandi wajib diisi",
+		"Invalid Email or Password":                 "Email atau kata sandi salah",
+		"Invalid Current Password":                  "Kata sandi saat ini salah",
+		"Duplicate Todo":                            "Todo sudah ada",
+		"Duplicate User":                            "Pengguna sudah ada",
+		"Duplicate Status":                          "Status sudah ada",
+		"Duplicate data":                            "Data sudah ada",
+		"Referenced data not found or still in use": "Data referensi tidak ditemukan atau masih digunakan",
+		"Required data is missing":                  "Data wajib tidak lengkap",
+		"Invalid data":                              "Data tidak valid",
+		"Concurrent update, please retry":           "Data sedang diubah, silakan coba lagi",
+		"Data was modified, please reload":          "Data telah diubah, silakan muat ulang",
+		"Database unavailable":                      "Database tidak tersedia",
+		"Status Not Found":                          "Status tidak ditemukan",
+		"Assignee Not Found %d":                     "Penanggung jawab %d tidak ditemukan",
+		"Status is used by %d todo":                 "Status digunakan oleh %d todo",
+		"Status is used by todo":                    "Status digunakan oleh todo",
+		"Todo status or assignee is in the trash":   "Status atau penanggung jawab todo ada di tempat sampah",
+		"Invalid reassign_to status":                "Status reassign_to tidak valid",
+		"Terminal Status Can't Have Transition":     "Status akhir tidak dapat memiliki transisi",
+		"Can't Change Status From %s To %s":         "Tidak dapat mengubah status dari %s ke %s",
+		"Invalid request body %s":                   "Body permintaan tidak valid %s",
+		"Invalid patch %s":                          "Patch tidak valid %s",
+		"Can't apply patch %s":                      "Patch tidak dapat diterapkan %s",
+		"Patch test failed":                         "Pengujian patch gagal",
+		"Invalid token":                             "Token tidak valid",
+		"Invalid cursor":                            "Cursor tidak valid",
+		"Invalid page %s":                           "Halaman %s tidak valid",
+		"Invalid limit %s":                          "Limit %s tidak valid",
+		"Invalid sort field %s":                     "Kolom pengurutan %s tidak valid",
+		"Invalid assignee_id %s":                    "assignee_id %s tidak valid",
+		"Invalid embed %s":                          "Embed %s tidak valid",
+		"Unsupported media type %s":                 "Media type %s tidak didukung",
+		"Invalid %s %s":                             "%s %s tidak valid",
+		"Invalid %s":                                "%s tidak valid",
+		"Required %s":                               "%s wajib diisi",
+		"%s Must Be At Least %d Characters":         "%s minimal %d karakter",
+		"%s Must Be At Most %d Characters":          "%s maksimal %d karakter",
+		"%s Must Have At Least %d Items":            "%s minimal %d item",
+		"Validation failed":                         "Validasi gagal",
+	},
+}
+
+// Translate translate the english message to the language,
+// the message is returned as is when the catalog doesn't have it
+func Translate(language string, message string) string {
+	if translated, ok := messages[language][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// T translate the catalog message to the request language, then format the message with the args if any
+func T(c *fiber.Ctx, message string, args ...interface{}) string {
+	message = Translate(GetLanguage(c), message)
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	return message
+}
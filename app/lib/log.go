@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// LogLevel severity of the log entry
+type LogLevel int
+
+const (
+	// LevelDebug detail for troubleshooting, include every SQL statement
+	LevelDebug LogLevel = iota
+	// LevelInfo normal operation, include the access log
+	LevelInfo
+	// LevelWarn unexpected but handled situation
+	LevelWarn
+	// LevelError failure which need attention
+	LevelError
+	// LevelSilent nothing is logged
+	LevelSilent
+)
+
+var levelNames = map[LogLevel]string{
+	LevelDebug:  "debug",
+	LevelInfo:   "info",
+	LevelWarn:   "warn",
+	LevelError:  "error",
+	LevelSilent: "silent",
+}
+
+func (l LogLevel) String() string {
+	return levelNames[l]
+}
+
+// ParseLogLevel parse debug, info, warn, error or silent, default to info
+func ParseLogLevel(level string) LogLevel {
+	for value, name := range levelNames {
+		if strings.EqualFold(strings.TrimSpace(level), name) {
+			return value
+		}
+	}
+	return LevelInfo
+}
+
+// GetLogLevel minimum level logged configured by LOG_LEVEL
+func GetLogLevel() LogLevel {
+	return ParseLogLevel(viper.GetString("LOG_LEVEL"))
+}
+
+// Fields structured fields of the log entry
+type Fields map[string]interface{}
+
+var (
+	logMutex  sync.Mutex
+	logOutput io.Writer = os.Stdout
+)
+
+// SetLogOutput set the writer of the log entries, default to stdout
+func SetLogOutput(output io.Writer) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	logOutput = output
+}
+
+// Log write the entry as a JSON line when the level is enabled
+func Log(level LogLevel, message string, fields Fields) {
+	if level < GetLogLevel() || level >= LevelSilent {
+		return
+	}
+
+	entry := Fields{}
+	for key, value := range fields {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		entry[key] = value
+	}
+	entry["time"] = time.Now().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = message
+
+	bte, err := json.Marshal(entry)
+	if nil != err {
+		bte, _ = json.Marshal(Fields{"time": entry["time"], "level": entry["level"], "msg": message, "error": err.Error()})
+	}
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	logOutput.Write(append(bte, '\n'))
+}
+
+// Debug log the entry at debug level
+func Debug(message string, fields Fields) {
+	Log(LevelDebug, message, fields)
+}
+
+// Info log the entry at info level
+func Info(message string, fields Fields) {
+	Log(LevelInfo, message, fields)
+}
+
+// Warn log the entry at warn level
+func Warn(message string, fields Fields) {
+	Log(LevelWarn, message, fields)
+}
+
+// Error log the entry at error level
+func Error(message string, fields Fields) {
+	Log(LevelError, message, fields)
+}
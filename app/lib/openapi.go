@@ -0,0 +1,457 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenAPI OpenAPI 3.1 document, only the part used to validate the request is decoded
+type OpenAPI struct {
+	Paths      map[string]*PathItem `json:"paths"`
+	Components struct {
+		Schemas    map[string]*Schema    `json:"schemas"`
+		Parameters map[string]*Parameter `json:"parameters"`
+	} `json:"components"`
+
+	routes []openAPIRoute
+}
+
+// Server base url of the paths
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem operations of the path, the servers are set on the paths served outside of the api endpoint
+type PathItem struct {
+	Servers    []Server    `json:"servers"`
+	Parameters []Parameter `json:"parameters"`
+	Get        *Operation  `json:"get"`
+	Put        *Operation  `json:"put"`
+	Post       *Operation  `json:"post"`
+	Delete     *Operation  `json:"delete"`
+	Patch      *Operation  `json:"patch"`
+}
+
+// Operation request of the path and method
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Parameters  []Parameter  `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+}
+
+// Parameter path, query or header parameter
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody request body schema by media type
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType schema of the media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema subset of the JSON Schema 2020-12 keywords used by the request of the api document,
+// the other keywords are ignored
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       SchemaType         `json:"type"`    // integer, string, boolean, array, object or null
+	Format     string             `json:"format"`  // date or email, the other formats aren't checked
+	Pattern    string             `json:"pattern"` // regular expression of the string
+	Enum       []interface{}      `json:"enum"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	MinItems   *int               `json:"minItems"`
+	Items      *Schema            `json:"items"`
+	Properties map[string]*Schema `json:"properties"`
+	Required   []string           `json:"required"`
+	AllOf      []*Schema          `json:"allOf"`
+
+	pattern *regexp.Regexp
+}
+
+// SchemaType json types of the schema, a single type or a list such as ["string", "null"]
+type SchemaType []string
+
+// UnmarshalJSON accept both the single type and the list of type
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); nil == err {
+		*t = SchemaType{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); nil != err {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// openAPIRoute matcher of the path template, ex: /todos/{id}
+type openAPIRoute struct {
+	path    string
+	pattern *regexp.Regexp
+	params  []string
+	item    *PathItem
+}
+
+// ParseOpenAPI decode the OpenAPI document, resolve the parameter references
+// and merge the path parameters into every operation of the path
+func ParseOpenAPI(data []byte) (*OpenAPI, error) {
+	spec := &OpenAPI{}
+	if err := json.Unmarshal(data, spec); nil != err {
+		return nil, err
+	}
+
+	for path, item := range spec.Paths {
+		shared, err := spec.resolveParameters(item.Parameters)
+		if nil != err {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		for _, operation := range item.operations() {
+			parameters, err := spec.resolveParameters(operation.Parameters)
+			if nil != err {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			operation.Parameters = append(parameters, shared...)
+		}
+
+		params := []string{}
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				params = append(params, segment[1:len(segment)-1])
+				segments[i] = "([^/]+)"
+			} else {
+				segments[i] = regexp.QuoteMeta(segment)
+			}
+		}
+		spec.routes = append(spec.routes, openAPIRoute{
+			path:    path,
+			pattern: regexp.MustCompile("^" + strings.Join(segments, "/") + "/?$"),
+			params:  params,
+			item:    item,
+		})
+	}
+	// the literal path first, ex: /users/me before /users/{id}
+	sort.Slice(spec.routes, func(i, j int) bool {
+		if len(spec.routes[i].params) != len(spec.routes[j].params) {
+			return len(spec.routes[i].params) < len(spec.routes[j].params)
+		}
+		return spec.routes[i].path < spec.routes[j].path
+	})
+
+	return spec, spec.compilePatterns()
+}
+
+// Operation operation of the request method and path with the path parameter values,
+// the paths are served under the base path unless the path item has its own servers,
+// nil when the document doesn't describe the request
+func (spec *OpenAPI) Operation(method string, path string, basePath string) (*Operation, map[string]string) {
+	basePath = strings.TrimSuffix(basePath, "/")
+	for _, route := range spec.routes {
+		relative := path
+		if len(route.item.Servers) == 0 {
+			if !strings.HasPrefix(path, basePath) {
+				continue
+			}
+			relative = strings.TrimPrefix(path, basePath)
+			if relative == "" {
+				relative = "/"
+			}
+		}
+		match := route.pattern.FindStringSubmatch(relative)
+		if match == nil {
+			continue
+		}
+		operation := route.item.operation(method)
+		if nil == operation {
+			continue
+		}
+		values := map[string]string{}
+		for i, name := range route.params {
+			values[name] = match[i+1]
+		}
+		return operation, values
+	}
+	return nil, nil
+}
+
+// ValidateParameter validate the raw value of the parameter, the integer value is converted first,
+// empty value is only checked by the required flag
+func (spec *OpenAPI) ValidateParameter(parameter Parameter, value string, present bool) []FieldError {
+	errors := []FieldError{}
+	if !present || value == "" {
+		if parameter.Required {
+			errors = append(errors, NewFieldError(parameter.Name, CodeRequired, "Required %s", fieldLabel(parameter.Name)))
+		}
+		return errors
+	}
+
+	schema := spec.resolve(parameter.Schema)
+	if nil == schema {
+		return errors
+	}
+	var converted interface{} = value
+	if schema.Type.has("integer") {
+		number, err := strconv.ParseInt(value, 10, 64)
+		if nil != err {
+			return append(errors, NewFieldError(parameter.Name, CodeInvalidType, "Invalid %s", fieldLabel(parameter.Name)))
+		}
+		converted = float64(number)
+	}
+	spec.validate(schema, parameter.Name, converted, &errors)
+	return errors
+}
+
+// Validate validate the json decoded value against the schema, return every violation,
+// the error field is the path of the value, ex: assignee_ids.0
+func (spec *OpenAPI) Validate(schema *Schema, value interface{}) []FieldError {
+	errors := []FieldError{}
+	spec.validate(schema, "", value, &errors)
+	return errors
+}
+
+func (spec *OpenAPI) validate(schema *Schema, name string, value interface{}, errors *[]FieldError) {
+	schema = spec.resolve(schema)
+	if nil == schema {
+		return
+	}
+	field := name
+	if field == "" {
+		field = "body"
+	}
+
+	for _, sub := range schema.AllOf {
+		spec.validate(sub, name, value, errors)
+	}
+	if len(schema.Type) > 0 && !schema.Type.accept(value) {
+		*errors = append(*errors, NewFieldError(field, CodeInvalidType, "Invalid %s", fieldLabel(field)))
+		return
+	}
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		*errors = append(*errors, NewFieldError(field, CodeInvalidValue, "Invalid %s", fieldLabel(field)))
+		return
+	}
+
+	switch value := value.(type) {
+	case string:
+		rules := []string{}
+		if nil != schema.MinLength {
+			rules = append(rules, "min="+strconv.Itoa(*schema.MinLength))
+		}
+		if nil != schema.MaxLength {
+			rules = append(rules, "max="+strconv.Itoa(*schema.MaxLength))
+		}
+		if schema.Format == "date" || schema.Format == "email" {
+			rules = append(rules, schema.Format)
+		}
+		for _, rule := range rules {
+			if err := validateRule(field, rule, value); nil != err {
+				*errors = append(*errors, *err)
+			}
+		}
+		if nil != schema.pattern && !schema.pattern.MatchString(value) {
+			*errors = append(*errors, NewFieldError(field, CodeInvalidFormat, "Invalid %s", fieldLabel(field)))
+		}
+	case float64:
+		if (nil != schema.Minimum && value < *schema.Minimum) || (nil != schema.Maximum && value > *schema.Maximum) {
+			*errors = append(*errors, NewFieldError(field, CodeInvalidValue, "Invalid %s", fieldLabel(field)))
+		}
+	case []interface{}:
+		if nil != schema.MinItems && len(value) < *schema.MinItems {
+			*errors = append(*errors, NewFieldError(field, CodeMinItems, "%s Must Have At Least %d Items", fieldLabel(field), *schema.MinItems))
+		}
+		for i, item := range value {
+			spec.validate(schema.Items, childField(name, strconv.Itoa(i)), item, errors)
+		}
+	case map[string]interface{}:
+		for _, required := range schema.Required {
+			if _, ok := value[required]; !ok {
+				child := childField(name, required)
+				*errors = append(*errors, NewFieldError(child, CodeRequired, "Required %s", fieldLabel(child)))
+			}
+		}
+		properties := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+		for _, property := range properties {
+			if item, ok := value[property]; ok {
+				spec.validate(schema.Properties[property], childField(name, property), item, errors)
+			}
+		}
+	}
+}
+
+// resolve follow the schema reference to the components
+func (spec *OpenAPI) resolve(schema *Schema) *Schema {
+	for nil != schema && schema.Ref != "" {
+		schema = spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// resolveParameters replace the parameter references by the component parameters
+func (spec *OpenAPI) resolveParameters(parameters []Parameter) ([]Parameter, error) {
+	resolved := make([]Parameter, 0, len(parameters))
+	for _, parameter := range parameters {
+		if parameter.Ref != "" {
+			component, ok := spec.Components.Parameters[strings.TrimPrefix(parameter.Ref, "#/components/parameters/")]
+			if !ok {
+				return nil, fmt.Errorf("unknown parameter %s", parameter.Ref)
+			}
+			parameter = *component
+		}
+		resolved = append(resolved, parameter)
+	}
+	return resolved, nil
+}
+
+// compilePatterns compile the pattern of every schema once
+func (spec *OpenAPI) compilePatterns() error {
+	seen := map[*Schema]bool{}
+	var compile func(schema *Schema) error
+	compile = func(schema *Schema) error {
+		if nil == schema || seen[schema] {
+			return nil
+		}
+		seen[schema] = true
+		if schema.Pattern != "" {
+			pattern, err := regexp.Compile(schema.Pattern)
+			if nil != err {
+				return err
+			}
+			schema.pattern = pattern
+		}
+		children := append([]*Schema{schema.Items}, schema.AllOf...)
+		for _, property := range schema.Properties {
+			children = append(children, property)
+		}
+		for _, child := range children {
+			if err := compile(child); nil != err {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, schema := range spec.Components.Schemas {
+		if err := compile(schema); nil != err {
+			return err
+		}
+	}
+	for _, parameter := range spec.Components.Parameters {
+		if err := compile(parameter.Schema); nil != err {
+			return err
+		}
+	}
+	for _, item := range spec.Paths {
+		for _, operation := range item.operations() {
+			for _, parameter := range operation.Parameters {
+				if err := compile(parameter.Schema); nil != err {
+					return err
+				}
+			}
+			if nil != operation.RequestBody {
+				for _, media := range operation.RequestBody.Content {
+					if err := compile(media.Schema); nil != err {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// operation operation of the http method, HEAD is served by the GET operation
+func (item *PathItem) operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD":
+		return item.Get
+	case "PUT":
+		return item.Put
+	case "POST":
+		return item.Post
+	case "DELETE":
+		return item.Delete
+	case "PATCH":
+		return item.Patch
+	}
+	return nil
+}
+
+// operations every operation of the path
+func (item *PathItem) operations() []*Operation {
+	operations := []*Operation{}
+	for _, operation := range []*Operation{item.Get, item.Put, item.Post, item.Delete, item.Patch} {
+		if nil != operation {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// has whether the type is one of the types
+func (t SchemaType) has(name string) bool {
+	for _, item := range t {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+// accept whether the json decoded value is one of the types
+func (t SchemaType) accept(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return t.has("null")
+	case bool:
+		return t.has("boolean")
+	case float64:
+		return t.has("integer") && v == math.Trunc(v)
+	case string:
+		return t.has("string")
+	case []interface{}:
+		return t.has("array")
+	case map[string]interface{}:
+		return t.has("object")
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// childField path of the child value, ex: assignee_ids.0
+func childField(name string, child string) string {
+	if name == "" {
+		return child
+	}
+	return name + "." + child
+}
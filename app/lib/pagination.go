@@ -0,0 +1,108 @@
+package lib
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// DefaultPageLimit number of items per page when limit is not given
+	DefaultPageLimit = 20
+	// MaxPageLimit maximum number of items per page
+	MaxPageLimit = 100
+)
+
+// Pagination page request parsed from query string
+type Pagination struct {
+	Page  int      // current page, start from 1
+	Limit int      // items per page
+	Sort  []string // order clauses, ex: "due_date desc"
+}
+
+// Offset number of rows to skip
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// Page paginated response envelope
+type Page struct {
+	Items      interface{} `json:"items"`       // list of items
+	Total      int64       `json:"total"`       // total items matching the filter
+	Page       int         `json:"page"`        // current page
+	Limit      int         `json:"limit"`       // items per page
+	TotalPages int         `json:"total_pages"` // total pages
+}
+
+// GetPagination parse page, limit and sort query parameter,
+// sortable maps the sort field accepted from client to database column
+func GetPagination(c *fiber.Ctx, sortable map[string]string) (Pagination, error) {
+	p := Pagination{
+		Page:  1,
+		Limit: DefaultPageLimit,
+	}
+
+	if page := c.Query("page"); page != "" {
+		value, err := strconv.Atoi(page)
+		if err != nil || value < 1 {
+			return p, NewRequestError("Invalid page %s", page)
+		}
+		p.Page = value
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > MaxPageLimit {
+			return p, NewRequestError("Invalid limit %s", limit)
+		}
+		p.Limit = value
+	}
+
+	sort, err := ParseSort(c.Query("sort"), sortable)
+	if err != nil {
+		return p, err
+	}
+	p.Sort = sort
+
+	return p, nil
+}
+
+// ParseSort parse comma separated sort fields, prefix field with "-" for descending order
+func ParseSort(sort string, sortable map[string]string) ([]string, error) {
+	clauses := []string{}
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		direction := "asc"
+		if strings.HasPrefix(field, "-") {
+			direction = "desc"
+			field = field[1:]
+		} else if strings.HasPrefix(field, "+") {
+			field = field[1:]
+		}
+
+		column, ok := sortable[field]
+		if !ok {
+			return nil, NewRequestError("Invalid sort field %s", field)
+		}
+		clauses = append(clauses, column+" "+direction)
+	}
+
+	return clauses, nil
+}
+
+// SendPage send http 200 response with paginated items
+func SendPage(c *fiber.Ctx, p Pagination, total int64, items interface{}) error {
+	return OK(c, Page{
+		Items:      items,
+		Total:      total,
+		Page:       p.Page,
+		Limit:      p.Limit,
+		TotalPages: int(math.Ceil(float64(total) / float64(p.Limit))),
+	})
+}
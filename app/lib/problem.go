@@ -0,0 +1,191 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// ProblemContentType media type of the RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBase prefix of the problem type uri, followed by the problem code
+const ProblemTypeBase = "/problems/"
+
+// Problem error codes, stable between releases
+const (
+	CodeBadRequest          = "bad_request"
+	CodeUnauthorized        = "unauthorized"
+	CodeForbidden           = "forbidden"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodePreconditionFailed  = "precondition_failed"
+	CodeUnprocessableEntity = "unprocessable_entity"
+	CodeInternal            = "internal_error"
+	CodeValidationFailed    = "validation_failed"
+	CodeInvalidToken        = "invalid_token"
+	CodeInvalidTransition   = "invalid_transition"
+	CodeStatusInUse         = "status_in_use"
+	CodeDuplicate           = "duplicate"
+)
+
+// problemCodes default problem code by http status
+var problemCodes = map[int]string{
+	400: CodeBadRequest,
+	401: CodeUnauthorized,
+	403: CodeForbidden,
+	404: CodeNotFound,
+	409: CodeConflict,
+	412: CodePreconditionFailed,
+	422: CodeUnprocessableEntity,
+	500: CodeInternal,
+}
+
+// Problem RFC 7807 problem details error response
+type Problem struct {
+	Type      string        `json:"type"`                 // problem type uri, ex: /problems/not_found
+	Title     string        `json:"title"`                // short summary of the problem type
+	Status    int           `json:"status"`               // http status
+	Detail    string        `json:"detail,omitempty"`     // explanation of this occurrence of the problem, catalog message
+	Args      []interface{} `json:"-"`                    // args of the detail message
+	Instance  string        `json:"instance,omitempty"`   // request path of this occurrence
+	Code      string        `json:"code"`                 // machine readable error code
+	RequestID string        `json:"request_id,omitempty"` // request id to correlate with the server log
+	Errors    []FieldError  `json:"errors,omitempty"`     // validation errors
+	Data      interface{}   `json:"data,omitempty"`       // additional problem data
+}
+
+// SendProblem send problem details response, the missing type, title, code, instance and request id are filled
+// from the request, the title, detail and field error messages are translated to the request language
+func SendProblem(c *fiber.Ctx, problem Problem) error {
+	if problem.Status == 0 {
+		problem.Status = 500
+	}
+	if problem.Code == "" {
+		problem.Code = problemCodes[problem.Status]
+		if problem.Code == "" {
+			problem.Code = strings.ReplaceAll(strings.ToLower(utils.StatusMessage(problem.Status)), " ", "_")
+		}
+	}
+	if problem.Type == "" {
+		problem.Type = ProblemTypeBase + problem.Code
+	}
+	if problem.Title == "" {
+		problem.Title = utils.StatusMessage(problem.Status)
+	}
+	problem.Title = T(c, problem.Title)
+	problem.Detail = T(c, problem.Detail, problem.Args...)
+	if problem.Instance == "" {
+		problem.Instance = c.OriginalURL()
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = GetRequestID(c)
+	}
+	if len(problem.Errors) > 0 {
+		translated := make([]FieldError, len(problem.Errors))
+		for i, err := range problem.Errors {
+			// the field label args are catalog messages too
+			args := make([]interface{}, len(err.Args))
+			for j, arg := range err.Args {
+				if label, ok := arg.(string); ok {
+					arg = T(c, label)
+				}
+				args[j] = arg
+			}
+			translated[i] = err
+			translated[i].Message = T(c, err.Message, args...)
+		}
+		problem.Errors = translated
+	}
+
+	bte, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, ProblemContentType)
+	return c.Status(problem.Status).Send(bte)
+}
+
+// GetRequestID request id set by the request id middleware or sent by the client
+func GetRequestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals("requestid").(string); ok && id != "" {
+		return id
+	}
+	if id := string(c.Response().Header.Peek(fiber.HeaderXRequestID)); id != "" {
+		return id
+	}
+	return c.Get(fiber.HeaderXRequestID)
+}
+
+// logError log the internal error with the request id, the error is never sent to client
+func logError(c *fiber.Ctx, err error) {
+	Error("request failed", Fields{
+		"request_id": GetRequestID(c),
+		"method":     c.Method(),
+		"path":       c.OriginalURL(),
+		"error":      err,
+	})
+}
+
+// HTTPError error which know its http status and problem code
+type HTTPError interface {
+	error
+	HTTPStatus() int
+	Code() string
+}
+
+// RequestError invalid request with the catalog message and its args, sent as http 400
+type RequestError struct {
+	Message string
+	Args    []interface{}
+}
+
+// NewRequestError invalid request error of the catalog message formatted with the args
+func NewRequestError(message string, args ...interface{}) *RequestError {
+	return &RequestError{Message: message, Args: args}
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf(e.Message, e.Args...)
+}
+
+// HTTPStatus http 400 bad request
+func (e *RequestError) HTTPStatus() int {
+	return 400
+}
+
+// Code bad request problem code
+func (e *RequestError) Code() string {
+	return CodeBadRequest
+}
+
+// SendError send the problem of the HTTPError with the error message as detail unless the message is given,
+// other error is sent as http 500, server errors are logged and never sent to client
+func SendError(c *fiber.Ctx, err error, message ...string) error {
+	var e HTTPError
+	ok := errors.As(err, &e)
+	if !ok || e.HTTPStatus() >= 500 {
+		if cause, ok := err.(interface{ Cause() error }); ok {
+			logError(c, cause.Cause())
+		} else {
+			logError(c, err)
+		}
+	}
+	if !ok {
+		return SendProblem(c, Problem{Status: 500, Detail: "Internal server error"})
+	}
+
+	problem := Problem{Status: e.HTTPStatus(), Code: e.Code(), Detail: e.Error()}
+	var request *RequestError
+	if errors.As(err, &request) {
+		problem.Detail, problem.Args = request.Message, request.Args
+	}
+	if len(message) > 0 && message[0] != "" {
+		problem.Detail = message[0]
+	}
+	return SendProblem(c, problem)
+}
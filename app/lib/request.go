@@ -0,0 +1,116 @@
+package lib
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// actorKey locals key of the acting user id
+const actorKey = "actor"
+
+// actorContextKey context key of the acting user id
+type actorContextKey struct{}
+
+// requestIDContextKey context key of the request id
+type requestIDContextKey struct{}
+
+// userIDPattern numeric user id
+var userIDPattern = regexp.MustCompile(`^[1-9][0-9]{0,18}$`)
+
+// GetXUserID get X-User-ID header, nil when it is empty or neither a UUID nor a user id
+func GetXUserID(c *fiber.Ctx) *string {
+	id := c.Get("X-User-ID")
+	if id == "" {
+		return nil
+	}
+	if _, err := uuid.Parse(id); err != nil && !userIDPattern.MatchString(id) {
+		return nil
+	}
+	return &id
+}
+
+// SetActor set the acting user id of the request, used by the authentication
+func SetActor(c *fiber.Ctx, id string) {
+	c.Locals(actorKey, id)
+}
+
+// GetActor get the acting user id of the request, the authenticated user take precedence over X-User-ID header
+func GetActor(c *fiber.Ctx) *string {
+	if id, ok := c.Locals(actorKey).(string); ok {
+		return &id
+	}
+	return GetXUserID(c)
+}
+
+// Context request context carrying the acting user id and the request id,
+// use it with gorm WithContext so the model can record who created or updated the data
+// and the query log can be correlated to the request
+func Context(c *fiber.Ctx) context.Context {
+	ctx := c.UserContext()
+	if actor := GetActor(c); nil != actor {
+		ctx = context.WithValue(ctx, actorContextKey{}, *actor)
+	}
+	if id := GetRequestID(c); id != "" {
+		ctx = context.WithValue(ctx, requestIDContextKey{}, id)
+	}
+	return ctx
+}
+
+// RequestIDFromContext get the request id carried by the context, empty when there is none
+func RequestIDFromContext(ctx context.Context) string {
+	if nil == ctx {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// ActorFromContext get the acting user id carried by the context
+func ActorFromContext(ctx context.Context) *string {
+	if nil == ctx {
+		return nil
+	}
+	if id, ok := ctx.Value(actorContextKey{}).(string); ok {
+		return &id
+	}
+	return nil
+}
+
+// DefaultLanguage language used when the client doesn't accept any valid language
+const DefaultLanguage = "en"
+
+// languagePattern primary language subtag
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// GetLanguage negotiate the primary language of the highest quality Accept-Language,
+// default to DefaultLanguage
+func GetLanguage(c *fiber.Ctx) string {
+	language := DefaultLanguage
+	best := 0.0
+	for _, part := range strings.Split(c.Get(fiber.HeaderAcceptLanguage), ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = value
+				}
+			}
+		}
+
+		primary := strings.Split(tag, "-")[0]
+		if quality > best && languagePattern.MatchString(primary) {
+			language = primary
+			best = quality
+		}
+	}
+
+	return language
+}
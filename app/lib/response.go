@@ -6,52 +6,101 @@ import (
 
 // Response http response
 type Response struct {
-	Status  int    `json:"status"`  // http status
-	Message string `json:"message"` // response message
+	Status  int         `json:"status"`         // http status
+	Message string      `json:"message"`        // response message
+	Data    interface{} `json:"data,omitempty"` // additional response data
 }
 
-// Send response
+// Send response, the message is translated to the request language
 func Send(c *fiber.Ctx, status int, message string) error {
 	return c.Status(status).JSON(Response{
 		Status:  status,
-		Message: message,
+		Message: T(c, message),
 	})
 }
 
-// ErrorBadRequest send http 400 bad request
-func ErrorBadRequest(c *fiber.Ctx, message ...string) error {
+// SendData response with additional data, the message is translated to the request language
+func SendData(c *fiber.Ctx, status int, message string, data interface{}) error {
+	return c.Status(status).JSON(Response{
+		Status:  status,
+		Message: T(c, message),
+		Data:    data,
+	})
+}
+
+// ErrorBadRequest send http 400 bad request problem, the catalog message is formatted with the args
+func ErrorBadRequest(c *fiber.Ctx, message string, args ...interface{}) error {
+	if message == "" {
+		message = "Bad request"
+	}
+
+	return SendProblem(c, Problem{Status: 400, Detail: message, Args: args})
+}
+
+// ErrorUnauthorized send http 401 unauthorized problem
+func ErrorUnauthorized(c *fiber.Ctx, message ...string) error {
 	if len(message) == 0 {
-		message = append(message, "Bad request")
+		message = append(message, "Unauthorized")
 	}
 
-	return Send(c, 400, message[0])
+	return SendProblem(c, Problem{Status: 401, Detail: message[0]})
 }
 
-// ErrorNotFound send http 404 not found
+// ErrorInvalidToken send http 401 invalid token problem
+func ErrorInvalidToken(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+	return SendProblem(c, Problem{Status: 401, Code: CodeInvalidToken, Detail: "Invalid token"})
+}
+
+// ErrorForbidden send http 403 forbidden problem
+func ErrorForbidden(c *fiber.Ctx, message ...string) error {
+	if len(message) == 0 {
+		message = append(message, "Forbidden")
+	}
+
+	return SendProblem(c, Problem{Status: 403, Detail: message[0]})
+}
+
+// ErrorNotFound send http 404 not found problem
 func ErrorNotFound(c *fiber.Ctx, message ...string) error {
 	if len(message) == 0 {
 		message = append(message, "Not found")
 	}
 
-	return Send(c, 404, message[0])
+	return SendProblem(c, Problem{Status: 404, Detail: message[0]})
 }
 
-// ErrorInternal send http 500 internal server error
-func ErrorInternal(c *fiber.Ctx, message ...string) error {
-	if len(message) == 0 {
-		message = append(message, "Internal server error")
+// ErrorInternal send http 500 internal server error problem,
+// the errors are logged with the request id and never sent to client
+func ErrorInternal(c *fiber.Ctx, errors ...error) error {
+	for _, err := range errors {
+		logError(c, err)
 	}
 
-	return Send(c, 500, message[0])
+	return SendProblem(c, Problem{Status: 500, Detail: "Internal server error"})
 }
 
-// ErrorConflict send http 409 conflict
+// ErrorConflict send http 409 conflict problem
 func ErrorConflict(c *fiber.Ctx, message ...string) error {
 	if len(message) == 0 {
 		message = append(message, "Conflict")
 	}
 
-	return Send(c, 409, message[0])
+	return SendProblem(c, Problem{Status: 409, Detail: message[0]})
+}
+
+// ErrorPreconditionFailed send http 412 precondition failed problem, the record changed since the client read it
+func ErrorPreconditionFailed(c *fiber.Ctx) error {
+	return SendProblem(c, Problem{Status: 412, Detail: "Data was modified, please reload"})
+}
+
+// ErrorUnprocessableEntity send http 422 unprocessable entity problem with additional data
+func ErrorUnprocessableEntity(c *fiber.Ctx, message string, data interface{}) error {
+	if message == "" {
+		message = "Unprocessable entity"
+	}
+
+	return SendProblem(c, Problem{Status: 422, Detail: message, Data: data})
 }
 
 // OK send http 200 response
@@ -59,7 +108,7 @@ func OK(c *fiber.Ctx, result ...interface{}) error {
 	if len(result) == 0 {
 		result = append(result, Response{
 			Status:  200,
-			Message: "success",
+			Message: T(c, "success"),
 		})
 	}
 
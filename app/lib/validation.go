@@ -0,0 +1,185 @@
+package lib
+
+import (
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FieldError validation error of a field
+type FieldError struct {
+	Field   string        `json:"field"`   // json field name
+	Code    string        `json:"code"`    // machine readable error code
+	Message string        `json:"message"` // catalog message, translated and formatted when sent
+	Args    []interface{} `json:"-"`       // args of the message, the string args are translated too
+}
+
+// Validation error codes
+const (
+	CodeRequired      = "required"
+	CodeMinLength     = "min_length"
+	CodeMaxLength     = "max_length"
+	CodeInvalidDate   = "invalid_date"
+	CodeInvalidEmail  = "invalid_email"
+	CodeInvalidFormat = "invalid_format"
+	CodeInvalidType   = "invalid_type"
+	CodeInvalidValue  = "invalid_value"
+	CodeMinItems      = "min_items"
+)
+
+// colorPattern hex color, ex: #1abc9c
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate validate the struct fields by the `validate` tag, return every violation.
+// Rules are comma separated: required, required=create, min=N, max=N (characters), date, email, color.
+// The required rule apply on "create" and "replace", required=create only on "create",
+// other rules apply on the non empty fields.
+func Validate(value interface{}, c string) []FieldError {
+	errors := []FieldError{}
+	validateStruct(reflect.Indirect(reflect.ValueOf(value)), c, &errors)
+	return errors
+}
+
+func validateStruct(value reflect.Value, c string, errors *[]FieldError) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateStruct(value.Field(i), c, errors)
+			continue
+		}
+
+		rules := field.Tag.Get("validate")
+		if rules == "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldValue := value.Field(i)
+		if isEmpty(fieldValue) {
+			if isRequired(rules, c) {
+				*errors = append(*errors, NewFieldError(name, CodeRequired, "Required %s", fieldLabel(name)))
+			}
+			continue
+		}
+
+		text, isText := "", false
+		if fieldValue.Kind() == reflect.Ptr && fieldValue.Elem().Kind() == reflect.String {
+			text, isText = fieldValue.Elem().String(), true
+		} else if fieldValue.Kind() == reflect.String {
+			text, isText = fieldValue.String(), true
+		}
+		if !isText {
+			continue
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+			if err := validateRule(name, strings.TrimSpace(rule), text); nil != err {
+				*errors = append(*errors, *err)
+			}
+		}
+	}
+}
+
+func validateRule(name string, rule string, text string) *FieldError {
+	parts := strings.SplitN(rule, "=", 2)
+	switch parts[0] {
+	case "min", "max":
+		limit, _ := strconv.Atoi(parts[len(parts)-1])
+		length := utf8.RuneCountInString(text)
+		if parts[0] == "min" && length < limit {
+			err := NewFieldError(name, CodeMinLength, "%s Must Be At Least %d Characters", fieldLabel(name), limit)
+			return &err
+		}
+		if parts[0] == "max" && length > limit {
+			err := NewFieldError(name, CodeMaxLength, "%s Must Be At Most %d Characters", fieldLabel(name), limit)
+			return &err
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", text); nil != err {
+			if _, err := time.Parse(time.RFC3339, text); nil != err {
+				err := NewFieldError(name, CodeInvalidDate, "Invalid %s", fieldLabel(name))
+				return &err
+			}
+		}
+	case "email":
+		if address, err := mail.ParseAddress(text); nil != err || address.Address != text {
+			err := NewFieldError(name, CodeInvalidEmail, "Invalid %s", fieldLabel(name))
+			return &err
+		}
+	case "color":
+		if !colorPattern.MatchString(text) {
+			err := NewFieldError(name, CodeInvalidFormat, "Invalid %s", fieldLabel(name))
+			return &err
+		}
+	}
+	return nil
+}
+
+// NewFieldError field error with the catalog message and its args
+func NewFieldError(field string, code string, message string, args ...interface{}) FieldError {
+	return FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+		Args:    args,
+	}
+}
+
+// ErrorValidation send http 400 validation failed problem with the field errors
+func ErrorValidation(c *fiber.Ctx, errors []FieldError) error {
+	return SendProblem(c, Problem{
+		Status: 400,
+		Code:   CodeValidationFailed,
+		Detail: "Validation failed",
+		Errors: errors,
+	})
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return value.Len() == 0
+	}
+	return false
+}
+
+// isRequired check whether the empty field is a violation of the rules on the validation c
+func isRequired(rules string, c string) bool {
+	if hasRule(rules, "required=create") {
+		return c == "create"
+	}
+	return hasRule(rules, "required") && (c == "create" || c == "replace")
+}
+
+func hasRule(rules string, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldLabel human readable label of the json field name, ex: due_date to Due Date
+func fieldLabel(name string) string {
+	words := strings.Split(name, "_")
+	for i, word := range words {
+		if word == "id" || word == "ids" {
+			words[i] = strings.ToUpper(word)
+		} else if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
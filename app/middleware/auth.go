@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// userKey locals key of the authenticated user
+const userKey = "user"
+
+// Auth protect the route with the bearer access token of the user in the repository,
+// the authenticated user is available with GetUser
+func Auth(users repository.UserRepository) fiber.Handler {
+	return auth(users.Find)
+}
+
+// AuthTrashed Auth accepting the user in the trash too, for the routes of the owner restoring or purging the account
+func AuthTrashed(users repository.UserRepository) fiber.Handler {
+	return auth(users.FindWithTrashed)
+}
+
+// auth authenticate the access token of the user found by id
+func auth(find func(ctx context.Context, id int) (*model.User, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authorization := c.Get(fiber.HeaderAuthorization)
+		if !strings.HasPrefix(strings.ToLower(authorization), "bearer ") {
+			return lib.ErrorUnauthorized(c)
+		}
+
+		claims, err := services.ParseToken(strings.TrimSpace(authorization[7:]), services.TokenTypeAccess)
+		if err != nil {
+			return lib.ErrorInvalidToken(c)
+		}
+		userID, err := strconv.Atoi(claims.Subject)
+		if err != nil {
+			return lib.ErrorInvalidToken(c)
+		}
+
+		user, err := find(lib.Context(c), userID)
+		if errors.Is(err, services.ErrNotFound) {
+			return lib.ErrorInvalidToken(c)
+		} else if err != nil {
+			return lib.SendError(c, services.TranslateError(err))
+		}
+		c.Locals(userKey, user)
+		lib.SetActor(c, strconv.Itoa(user.ID))
+
+		return c.Next()
+	}
+}
+
+// GetUser authenticated user of the request, nil on public route
+func GetUser(c *fiber.Ctx) *model.User {
+	if user, ok := c.Locals(userKey).(*model.User); ok {
+		return user
+	}
+	return nil
+}
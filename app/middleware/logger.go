@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+// requestIDPattern incoming request id accepted as is, other value is replaced to keep the log safe
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID use the incoming X-Request-ID or generate new one,
+// the request id is sent back in the response header and available with lib.GetRequestID
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if requestIDPattern.MatchString(id) {
+			// the header is backed by the request buffer which is reused
+			id = utils.CopyString(id)
+		} else {
+			id = uuid.New().String()
+		}
+		c.Set(fiber.HeaderXRequestID, id)
+		c.Locals("requestid", id)
+		return c.Next()
+	}
+}
+
+// AccessLog log every request with its request id, route, status, latency and acting user
+func AccessLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		fields := lib.Fields{
+			"request_id": lib.GetRequestID(c),
+			"method":     c.Method(),
+			"route":      routeLabel(c),
+			"path":       c.OriginalURL(),
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"ip":         c.IP(),
+		}
+		if user := lib.GetActor(c); nil != user {
+			fields["user"] = *user
+		}
+
+		level := lib.LevelInfo
+		if status >= 500 {
+			level = lib.LevelError
+		}
+		lib.Log(level, "request", fields)
+		return err
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+var (
+	requestTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Handled http requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of the http requests by method, route and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	requestInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Http requests being handled.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requestTotal, requestDuration, requestInFlight)
+}
+
+// unmatchedRoute route label of the request which didn't reach a route handler, ex: not found or rejected by Auth,
+// the raw path would make the label cardinality unbounded
+const unmatchedRoute = "unmatched"
+
+// Metrics observe the count and the duration of the requests by the route pattern
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		requestInFlight.Inc()
+		defer requestInFlight.Dec()
+
+		err := c.Next()
+
+		status, route := responseStatus(c, err), routeLabel(c)
+		// the method is backed by the request buffer which is reused, the label outlive the request
+		labels := prometheus.Labels{"method": utils.CopyString(c.Method()), "route": route, "status": strconv.Itoa(status)}
+		requestTotal.With(labels).Inc()
+		requestDuration.With(labels).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// responseStatus status code of the response, the error is turned into response
+// by the error handler after the middleware
+func responseStatus(c *fiber.Ctx, err error) int {
+	if nil == err {
+		return c.Response().StatusCode()
+	}
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// routeLabel pattern of the route which handled the request
+func routeLabel(c *fiber.Ctx) string {
+	if c.Route().Method == "USE" {
+		return unmatchedRoute
+	}
+	return c.Route().Path
+}
+
+// MetricsHandler expose the registered metrics in the prometheus text format
+func MetricsHandler() fiber.Handler {
+	handler := fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler())
+	return func(c *fiber.Ctx) error {
+		handler(c.Context())
+		return nil
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ValidateRequest reject the request whose path, query or header parameters or json body don't conform
+// to the OpenAPI document with the field errors, the request outside of the document is left to the router
+func ValidateRequest(spec *lib.OpenAPI, basePath string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		operation, params := spec.Operation(c.Method(), c.Path(), basePath)
+		if nil == operation {
+			return c.Next()
+		}
+
+		errors := []lib.FieldError{}
+		for _, parameter := range operation.Parameters {
+			var value string
+			var present bool
+			switch parameter.In {
+			case "path":
+				value, present = params[parameter.Name]
+			case "query":
+				value, present = c.Query(parameter.Name), c.Context().QueryArgs().Has(parameter.Name)
+			case "header":
+				value = c.Get(parameter.Name)
+				present = value != ""
+			}
+			errors = append(errors, spec.ValidateParameter(parameter, value, present)...)
+		}
+
+		if body := operation.RequestBody; nil != body {
+			if len(c.Body()) == 0 {
+				if body.Required {
+					return lib.ErrorBadRequest(c, "Required request body")
+				}
+			} else {
+				mediaType := strings.ToLower(strings.TrimSpace(strings.Split(c.Get(fiber.HeaderContentType), ";")[0]))
+				content, ok := body.Content[mediaType]
+				if !ok {
+					return lib.SendProblem(c, lib.Problem{
+						Status: fiber.StatusUnsupportedMediaType,
+						Detail: "Unsupported media type %s",
+						Args:   []interface{}{mediaType},
+					})
+				}
+				var value interface{}
+				if err := json.Unmarshal(c.Body(), &value); err != nil {
+					return lib.ErrorBadRequest(c, "Invalid request body %s", err.Error())
+				}
+				errors = append(errors, spec.Validate(content.Schema, value)...)
+			}
+		}
+
+		if len(errors) > 0 {
+			return lib.ErrorValidation(c, errors)
+		}
+		return c.Next()
+	}
+}
@@ -1,10 +1,190 @@
 package migrations
 
-import "github.com/razanlrahardjo/hacktiv8/app/model"
+import (
+	"errors"
+	"fmt"
+	"time"
 
-// ModelMigrations models to migrate
-var ModelMigrations []interface{} = []interface{}{
-	&model.Todo{},
-	&model.Status{},
-	&model.User{},
+	"gorm.io/gorm"
+)
+
+// migrationLockKey postgres advisory lock key held while a migration is applied or rolled back
+const migrationLockKey = 7238164500
+
+// ErrIrreversible migration doesn't have down step
+var ErrIrreversible = errors.New("migration can't be rolled back")
+
+// Migration versioned schema change
+type Migration struct {
+	Version     int64  // ordering of the migration, ex: 2021100100
+	Description string // short description shown by the status
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error // nil when the migration can't be rolled back
+}
+
+// Migrations every migration ordered by version, append new migration to the end
+var Migrations = []Migration{
+	{Version: 2021100100, Description: "create schema", Up: CreateSchema, Down: DropSchema},
+	{Version: 2021100200, Description: "move todo status to status table", Up: MigrateTodoStatus, Down: RollbackTodoStatus},
+	{Version: 2021100300, Description: "move todo person in charge to assignee", Up: MigrateTodoAssignee, Down: RollbackTodoAssignee},
+	{Version: 2021100400, Description: "fill status terminal flag", Up: MigrateStatusTerminal, Down: RollbackStatusTerminal},
+	{Version: 2021100500, Description: "add record version", Up: MigrateRecordVersion, Down: RollbackRecordVersion},
+	{Version: 2021100600, Description: "add status text unique index", Up: MigrateStatusTextUnique, Down: RollbackStatusTextUnique},
+}
+
+// SchemaMigration applied migration
+type SchemaMigration struct {
+	Version     int64     `gorm:"primaryKey;autoIncrement:false"`
+	Description string    `gorm:"type:varchar(256)"`
+	AppliedAt   time.Time `gorm:"type:timestamp"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus migration with the time it was applied, nil when it is pending
+type MigrationStatus struct {
+	Version     int64
+	Description string
+	AppliedAt   *time.Time
+}
+
+// Up apply the pending migrations in order, return the applied migrations
+func Up(db *gorm.DB) ([]Migration, error) {
+	applied := []Migration{}
+	for {
+		var migration *Migration
+		err := locked(db, func(tx *gorm.DB, done map[int64]SchemaMigration) error {
+			for i := range Migrations {
+				if _, ok := done[Migrations[i].Version]; !ok {
+					migration = &Migrations[i]
+					break
+				}
+			}
+			if nil == migration {
+				return nil
+			}
+
+			if err := migration.Up(tx); nil != err {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Description, err)
+			}
+			return tx.Create(&SchemaMigration{
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if nil != err || nil == migration {
+			return applied, err
+		}
+		applied = append(applied, *migration)
+	}
+}
+
+// Down roll back the last applied migrations, return the rolled back migrations
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	rolledBack := []Migration{}
+	for len(rolledBack) < steps {
+		var migration *Migration
+		err := locked(db, func(tx *gorm.DB, done map[int64]SchemaMigration) error {
+			for i := len(Migrations) - 1; i >= 0; i-- {
+				if _, ok := done[Migrations[i].Version]; ok {
+					migration = &Migrations[i]
+					break
+				}
+			}
+			if nil == migration {
+				return nil
+			}
+
+			if nil == migration.Down {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Description, ErrIrreversible)
+			}
+			if err := migration.Down(tx); nil != err {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Description, err)
+			}
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if nil != err || nil == migration {
+			return rolledBack, err
+		}
+		rolledBack = append(rolledBack, *migration)
+	}
+	return rolledBack, nil
+}
+
+// Status every migration with the time it was applied
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	done, err := appliedMigrations(db)
+	if nil != err {
+		return nil, err
+	}
+
+	status := []MigrationStatus{}
+	for _, migration := range Migrations {
+		item := MigrationStatus{Version: migration.Version, Description: migration.Description}
+		if applied, ok := done[migration.Version]; ok {
+			item.AppliedAt = &applied.AppliedAt
+		}
+		status = append(status, item)
+	}
+	return status, nil
+}
+
+// locked run the step in a transaction holding the migration lock,
+// so concurrent instances apply every migration once
+func locked(db *gorm.DB, step func(tx *gorm.DB, done map[int64]SchemaMigration) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// sqlite serialize the writers with the database lock
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; nil != err {
+				return err
+			}
+		}
+		if err := tx.Migrator().AutoMigrate(&SchemaMigration{}); nil != err {
+			return err
+		}
+
+		done, err := appliedMigrations(tx)
+		if nil != err {
+			return err
+		}
+		return step(tx, done)
+	})
+}
+
+// appliedMigrations applied migration by version, empty when the schema_migrations table doesn't exist yet
+func appliedMigrations(db *gorm.DB) (map[int64]SchemaMigration, error) {
+	done := map[int64]SchemaMigration{}
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return done, nil
+	}
+
+	records := []SchemaMigration{}
+	if err := db.Find(&records).Error; nil != err {
+		return nil, err
+	}
+	for _, record := range records {
+		done[record.Version] = record
+	}
+	return done, nil
+}
+
+// hasColumn check whether the table of the model has the column from the actual column types,
+// the sqlite migrator HasColumn match the create table statement and is fooled by foreign key references
+func hasColumn(db *gorm.DB, value interface{}, column string) bool {
+	if !db.Migrator().HasTable(value) {
+		return false
+	}
+	columnTypes, err := db.Migrator().ColumnTypes(value)
+	if nil != err {
+		return false
+	}
+	for _, columnType := range columnTypes {
+		if columnType.Name() == column {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// versionedTables tables embedding Base, which get the record version
+var versionedTables = []interface {
+	TableName() string
+}{&todo{}, &status{}, &user{}, &statusTransition{}, &refreshToken{}}
+
+// MigrateRecordVersion add the version column incremented on every update, the existing records start at 1
+func MigrateRecordVersion(db *gorm.DB) error {
+	for _, table := range versionedTables {
+		if hasColumn(db, table, "version") {
+			continue
+		}
+		if err := db.Exec("ALTER TABLE ? ADD COLUMN version bigint NOT NULL DEFAULT 1", clause.Table{Name: table.TableName()}).Error; nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// RollbackRecordVersion drop the version column
+func RollbackRecordVersion(db *gorm.DB) error {
+	for _, table := range versionedTables {
+		if !hasColumn(db, table, "version") {
+			continue
+		}
+		if err := db.Exec("ALTER TABLE ? DROP COLUMN version", clause.Table{Name: table.TableName()}).Error; nil != err {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The tables below are the frozen copy of the models when the versioned migration started,
+// later schema change must be a new migration instead of editing them.
+// The struct names are part of the many2many join column and constraint names.
+
+// Base frozen copy of model.Base, exported to be embedded by gorm
+type Base struct {
+	ID        int            `gorm:"primaryKey;unique;auto_increment"`
+	CreatedAt time.Time      `gorm:"type:timestamp"`
+	UpdatedAt time.Time      `gorm:"type:timestamp"`
+	CreatedBy *string        `gorm:"type:varchar(64)"`
+	UpdatedBy *string        `gorm:"type:varchar(64)"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+type todo struct {
+	Base
+	Title       *string `gorm:"type:text"`
+	Description *string `gorm:"type:text"`
+	DueDate     *string `gorm:"type:date"`
+	StatusID    *int    `gorm:"index"`
+	Status      *status `gorm:"foreignKey:StatusID"`
+	Assignees   []user  `gorm:"many2many:todo_assignee"`
+}
+
+func (todo) TableName() string {
+	return "todo"
+}
+
+type status struct {
+	Base
+	StatusText *string `gorm:"type:varchar(10)"`
+	IsTerminal *bool
+	Position   *int
+	Color      *string `gorm:"type:varchar(7)"`
+}
+
+func (status) TableName() string {
+	return "status"
+}
+
+type statusTransition struct {
+	Base
+	FromStatusID int     `gorm:"uniqueIndex:idx_status_transition"`
+	ToStatusID   int     `gorm:"uniqueIndex:idx_status_transition"`
+	FromStatus   *status `gorm:"foreignKey:FromStatusID"`
+	ToStatus     *status `gorm:"foreignKey:ToStatusID"`
+}
+
+func (statusTransition) TableName() string {
+	return "status_transition"
+}
+
+type user struct {
+	Base
+	Name         *string `gorm:"type:varchar(64)"`
+	Email        *string `gorm:"type:varchar(128);uniqueIndex"`
+	PasswordHash *string `gorm:"type:varchar(128)"`
+}
+
+func (user) TableName() string {
+	return "user"
+}
+
+type todoAssignee struct {
+	TodoID int
+	UserID int
+}
+
+func (todoAssignee) TableName() string {
+	return "todo_assignee"
+}
+
+type refreshToken struct {
+	Base
+	UserID    int        `gorm:"index"`
+	TokenID   string     `gorm:"type:varchar(64);uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"type:timestamp"`
+	RevokedAt *time.Time `gorm:"type:timestamp"`
+}
+
+func (refreshToken) TableName() string {
+	return "refresh_token"
+}
+
+// CreateSchema create the tables, the tables created by the former AutoMigrate are completed in place
+func CreateSchema(db *gorm.DB) error {
+	return db.AutoMigrate(&todo{}, &status{}, &user{}, &statusTransition{}, &refreshToken{})
+}
+
+// DropSchema drop the tables created by CreateSchema
+func DropSchema(db *gorm.DB) error {
+	return db.Migrator().DropTable(&todoAssignee{}, &refreshToken{}, &statusTransition{}, &todo{}, &user{}, &status{})
+}
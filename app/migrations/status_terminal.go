@@ -0,0 +1,14 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// MigrateStatusTerminal fill the terminal flag of the status created before the workflow existed,
+// "Done" and "Delete" were hardcoded as terminal status
+func MigrateStatusTerminal(db *gorm.DB) error {
+	return db.Exec(`UPDATE status SET is_terminal = (status_text IN ('Done', 'Delete')) WHERE is_terminal IS NULL`).Error
+}
+
+// RollbackStatusTerminal keep the filled terminal flag, it match the former hardcoded terminal status
+func RollbackStatusTerminal(db *gorm.DB) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MigrateStatusTextUnique add the unique index of the status text which identify the status
+// in the todo filter and the seed fixture, the duplicate status text must be renamed first
+func MigrateStatusTextUnique(db *gorm.DB) error {
+	duplicates := []string{}
+	if err := db.Unscoped().Model(&status{}).Where("status_text IS NOT NULL").Group("status_text").
+		Having("count(*) > 1").Pluck("status_text", &duplicates).Error; nil != err {
+		return err
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate status text %q, rename them before migrating", duplicates)
+	}
+	return db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_status_status_text ON status (status_text)").Error
+}
+
+// RollbackStatusTextUnique drop the unique index of the status text
+func RollbackStatusTextUnique(db *gorm.DB) error {
+	return db.Exec("DROP INDEX IF EXISTS idx_status_status_text").Error
+}
@@ -0,0 +1,117 @@
+package migrations
+
+import (
+	"strings"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// legacyTodoAssignee todo table before person in charge became the assignee relation
+type legacyTodoAssignee struct {
+	ID             int
+	PersonInCharge *string `gorm:"type:varchar(256)"`
+}
+
+func (legacyTodoAssignee) TableName() string {
+	return "todo"
+}
+
+// MigrateTodoAssignee move the legacy free-form todo.person_in_charge column to the todo_assignee relation,
+// comma separated names become multiple assignees matched to the existing user name,
+// the names which don't match any user are logged and not assigned
+func MigrateTodoAssignee(db *gorm.DB) error {
+	if !hasColumn(db, &legacyTodoAssignee{}, "person_in_charge") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		todos := []legacyTodoAssignee{}
+		if err := tx.Where("person_in_charge IS NOT NULL").Find(&todos).Error; err != nil {
+			return err
+		}
+
+		users := map[string]*user{}
+		for _, legacy := range todos {
+			assignees := []user{}
+			for _, name := range strings.Split(*legacy.PersonInCharge, ",") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+
+				assignee, ok := users[name]
+				if !ok {
+					found := user{}
+					if result := tx.Where("name = ?", name).Limit(1).Find(&found); result.Error != nil {
+						return result.Error
+					} else if result.RowsAffected > 0 {
+						assignee = &found
+					}
+					users[name] = assignee
+				}
+				if nil == assignee {
+					lib.Warn("person in charge doesn't match any user", lib.Fields{"todo_id": legacy.ID, "person_in_charge": name})
+					continue
+				}
+				assignees = append(assignees, *assignee)
+			}
+
+			if len(assignees) > 0 {
+				if err := tx.Model(&todo{Base: Base{ID: legacy.ID}}).Association("Assignees").Append(assignees); err != nil {
+					return err
+				}
+			}
+		}
+
+		// the sqlite migrator recreate the table to drop the column, which the assignee foreign key rejects
+		return tx.Exec("ALTER TABLE ? DROP COLUMN person_in_charge", clause.Table{Name: legacyTodoAssignee{}.TableName()}).Error
+	})
+}
+
+// RollbackTodoAssignee restore the todo.person_in_charge column from the assignee names and clear the assignees,
+// the names are truncated to the column length
+func RollbackTodoAssignee(db *gorm.DB) error {
+	if hasColumn(db, &legacyTodoAssignee{}, "person_in_charge") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&legacyTodoAssignee{}, "PersonInCharge"); err != nil {
+			return err
+		}
+
+		todos := []todo{}
+		if err := tx.Unscoped().Preload("Assignees").Find(&todos).Error; err != nil {
+			return err
+		}
+		for _, item := range todos {
+			names := []string{}
+			for _, assignee := range item.Assignees {
+				if assignee.Name != nil {
+					names = append(names, *assignee.Name)
+				}
+			}
+			if len(names) == 0 {
+				continue
+			}
+			personInCharge := truncate(strings.Join(names, ", "), 256)
+			if err := tx.Model(&legacyTodoAssignee{ID: item.ID}).Update("person_in_charge", personInCharge).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("1 = 1").Delete(&todoAssignee{}).Error
+	})
+}
+
+// truncate cut the text to the length in characters
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) > length {
+		return string(runes[:length])
+	}
+	return text
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// legacyTodo todo table before status became a foreign key
+type legacyTodo struct {
+	Status *string `gorm:"type:varchar(10)"`
+}
+
+func (legacyTodo) TableName() string {
+	return "todo"
+}
+
+// MigrateTodoStatus move the legacy free-form todo.status column to todo.status_id,
+// creating the missing status rows from the distinct legacy values
+func MigrateTodoStatus(db *gorm.DB) error {
+	if !hasColumn(db, &legacyTodo{}, "status") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Exec(`INSERT INTO status (status_text, created_at, updated_at)
+			SELECT DISTINCT todo.status, ?, ? FROM todo
+			WHERE todo.status IS NOT NULL AND NOT EXISTS (
+				SELECT 1 FROM status WHERE status.status_text = todo.status AND status.deleted_at IS NULL
+			)`, now, now).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(`UPDATE todo SET status_id = (
+				SELECT MIN(status.id) FROM status WHERE status.status_text = todo.status AND status.deleted_at IS NULL
+			) WHERE todo.status IS NOT NULL AND todo.status_id IS NULL`).Error; err != nil {
+			return err
+		}
+
+		return tx.Migrator().DropColumn(&legacyTodo{}, "status")
+	})
+}
+
+// RollbackTodoStatus restore the todo.status column from the status text, todo.status_id is kept
+func RollbackTodoStatus(db *gorm.DB) error {
+	if hasColumn(db, &legacyTodo{}, "status") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&legacyTodo{}, "Status"); err != nil {
+			return err
+		}
+
+		return tx.Exec(`UPDATE todo SET status = (
+				SELECT status.status_text FROM status WHERE status.id = todo.status_id
+			) WHERE todo.status_id IS NOT NULL`).Error
+	})
+}
@@ -1,16 +1,22 @@
 package model
 
 import (
-	"gorm.io/gorm"
 	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"gorm.io/gorm"
 )
 
 // Base model
 type Base struct {
-	ID        int           `json:"id,omitempty" gorm:"primaryKey;unique;auto_increment"`
-	CreatedAt time.Time     `json:"created_at,omitempty" gorm:"type:timestamp" format:"date-time" swaggertype:"string"`
-	UpdatedAt time.Time     `json:"updated_at,omitempty" gorm:"type:timestamp" format:"date-time" swaggertype:"string"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index" swaggerignore:"true"`
+	ID        int            `json:"id,omitempty" gorm:"primaryKey;unique;auto_increment"`
+	CreatedAt time.Time      `json:"created_at,omitempty" gorm:"type:timestamp"`
+	UpdatedAt time.Time      `json:"updated_at,omitempty" gorm:"type:timestamp"`
+	CreatedBy *string        `json:"created_by,omitempty" gorm:"type:varchar(64)"` // acting user id on create
+	UpdatedBy *string        `json:"updated_by,omitempty" gorm:"type:varchar(64)"` // acting user id on last update
+	Version   int            `json:"version,omitempty" gorm:"not null;default:1"`  // incremented on every update, compared on the concurrent update
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // BeforeCreate Data
@@ -18,6 +24,10 @@ func (b *Base) BeforeCreate(tx *gorm.DB) error {
 	now := time.Now()
 	b.CreatedAt = now
 	b.UpdatedAt = now
+	actor := lib.ActorFromContext(tx.Statement.Context)
+	b.CreatedBy = actor
+	b.UpdatedBy = actor
+	b.Version = 1
 	return nil
 }
 
@@ -25,5 +35,13 @@ func (b *Base) BeforeCreate(tx *gorm.DB) error {
 func (b *Base) BeforeUpdate(tx *gorm.DB) error {
 	now := time.Now()
 	b.UpdatedAt = now
+	// the creator can't be changed, the updater is always the acting user
+	tx.Statement.Omits = append(tx.Statement.Omits, "CreatedBy")
+	tx.Statement.SetColumn("UpdatedBy", lib.ActorFromContext(tx.Statement.Context))
 	return nil
 }
+
+// CursorKey position of the data in the (updated_at, id) ordering
+func (b Base) CursorKey() (time.Time, int) {
+	return b.UpdatedAt, b.ID
+}
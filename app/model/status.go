@@ -1,20 +1,32 @@
 package model
 
+import "github.com/razanlrahardjo/hacktiv8/app/lib"
+
 type Status struct {
 	Base
-	StatusText *string `json:"status_text,omitempty" gorm:"type:varchar(10)"`
+	StatusText *string `json:"status_text,omitempty" gorm:"type:varchar(10);uniqueIndex" validate:"required,max=10"`
+	IsTerminal *bool   `json:"is_terminal,omitempty"` // no transition allowed from terminal status
+	Position   *int    `json:"position,omitempty"`    // ordering of the status in the workflow
+	Color      *string `json:"color,omitempty" gorm:"type:varchar(7)" validate:"color"`
 }
 
 func (Status) TableName() string {
 	return "status"
 }
 
-func (Status *Status) Validation(c string) string {
-	switch c {
-	case "create":
-		if Status.StatusText == nil {
-			return "Required Status Text"
-		}
-	}
-	return ""
+func (Status *Status) Validation(c string) []lib.FieldError {
+	return lib.Validate(Status, c)
+}
+
+// StatusTransition allowed workflow edge from a status to the next status
+type StatusTransition struct {
+	Base
+	FromStatusID int     `json:"from_status_id" gorm:"uniqueIndex:idx_status_transition"`
+	ToStatusID   int     `json:"to_status_id" gorm:"uniqueIndex:idx_status_transition"`
+	FromStatus   *Status `json:"-" gorm:"foreignKey:FromStatusID"`
+	ToStatus     *Status `json:"to_status,omitempty" gorm:"foreignKey:ToStatusID"`
+}
+
+func (StatusTransition) TableName() string {
+	return "status_transition"
 }
@@ -1,41 +1,27 @@
 package model
 
+import "github.com/razanlrahardjo/hacktiv8/app/lib"
+
 type Todo struct {
 	Base
-	Title          *string `json:"title,omitempty" gorm:"type:text"`
-	Description    *string `json:"description,omitempty" gorm:"type:text"`
-	DueDate        *string `json:"due_date,omitempty" gorm:"type:date"`
-	PersonInCharge *string `json:"person_in_charge,omitempty" gorm:"type:varchar(256)"`
-	Status         *string `json:"status,omitempty" gorm:"type:varchar(10)"`
+	Title       *string `json:"title,omitempty" gorm:"type:text" validate:"required"`
+	Description *string `json:"description,omitempty" gorm:"type:text" validate:"required=create"` // required on create only, cleared by replace
+	DueDate     *string `json:"due_date,omitempty" gorm:"type:date" validate:"required,date"`
+	StatusID    *int    `json:"status_id,omitempty" gorm:"index"`
+	StatusText  *string `json:"status_text,omitempty" gorm:"-" validate:"max=10"` // set status by status text instead of id
+	Status      *Status `json:"status,omitempty" gorm:"foreignKey:StatusID"`
+	AssigneeIDs []int   `json:"assignee_ids,omitempty" gorm:"-" validate:"required"` // set assignees by user id
+	Assignees   []User  `json:"assignees,omitempty" gorm:"many2many:todo_assignee"`
 }
 
 func (Todo) TableName() string {
 	return "todo"
 }
 
-func (todo *Todo) Validation(c string) string {
-	switch c {
-	case "update":
-		if todo.Status != nil {
-			if *todo.Status == "Done" {
-				return "Can't Change Status When is Done"
-			}
-			if *todo.Status == "Delete" {
-				return "Can't Change Status When is Delete"
-			}
-		}
-	case "create":
-		if todo.Title == nil {
-			return "Required Title"
-		} else if todo.DueDate == nil {
-			return "Required Due Date"
-		} else if todo.Description == nil {
-			return "Required Description"
-		} else if todo.PersonInCharge == nil {
-			return "Required Person in Charge"
-		} else if todo.Status == nil {
-			return "Required Status"
-		}
+func (todo *Todo) Validation(c string) []lib.FieldError {
+	errors := lib.Validate(todo, c)
+	if (c == "create" || c == "replace") && todo.StatusID == nil && todo.StatusText == nil {
+		errors = append(errors, lib.NewFieldError("status_id", lib.CodeRequired, "Required %s", "Status"))
 	}
-	return ""
+	return errors
 }
@@ -1,20 +1,70 @@
 package model
 
+import (
+	"strings"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
 type User struct {
 	Base
-	Name *string `json:"name,omitempty" gorm:"type:varchar(64)"`
+	Name            *string `json:"name,omitempty" gorm:"type:varchar(64)" validate:"required,max=64"`
+	Email           *string `json:"email,omitempty" gorm:"type:varchar(128);uniqueIndex" validate:"required,email,max=128"`
+	Password        *string `json:"password,omitempty" gorm:"-" validate:"required=create,min=8,max=72"` // write only, stored as password hash, kept by replace
+	CurrentPassword *string `json:"current_password,omitempty" gorm:"-"`                                 // write only, required to change the password
+	PasswordHash    *string `json:"-" gorm:"type:varchar(128)"`
 }
 
 func (User) TableName() string {
 	return "user"
 }
 
-func (user *User) Validation(c string) string {
-	switch c {
-	case "create":
-		if user.Name == nil {
-			return "Required Name"
-		}
+func (user *User) Validation(c string) []lib.FieldError {
+	return lib.Validate(user, c)
+}
+
+// BeforeSave normalize the email and hash the password
+func (user *User) BeforeSave(tx *gorm.DB) error {
+	user.CurrentPassword = nil
+	if user.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*user.Email))
+		user.Email = &email
+	}
+	if user.Password == nil {
+		return nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(*user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	passwordHash := string(hash)
+	user.PasswordHash = &passwordHash
+	user.Password = nil
+	return nil
+}
+
+// CheckPassword compare the password with the password hash
+func (user *User) CheckPassword(password string) bool {
+	if user.PasswordHash == nil {
+		return false
 	}
-	return ""
+	return bcrypt.CompareHashAndPassword([]byte(*user.PasswordHash), []byte(password)) == nil
+}
+
+// RefreshToken issued refresh token, revoked on logout and rotation
+type RefreshToken struct {
+	Base
+	UserID    int        `json:"user_id" gorm:"index"`
+	TokenID   string     `json:"-" gorm:"type:varchar(64);uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"type:timestamp"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" gorm:"type:timestamp"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_token"
 }
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormStatusRepository StatusRepository stored with GORM
+type gormStatusRepository struct {
+	db *gorm.DB
+}
+
+// NewStatusRepository StatusRepository stored in the database
+func NewStatusRepository(db *gorm.DB) StatusRepository {
+	return &gormStatusRepository{db: db}
+}
+
+func (r *gormStatusRepository) Create(ctx context.Context, status *model.Status) error {
+	return services.TranslateError(r.db.WithContext(ctx).Create(status).Error)
+}
+
+func (r *gormStatusRepository) Find(ctx context.Context, id int) (*model.Status, error) {
+	status := model.Status{}
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&status).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return &status, nil
+}
+
+func (r *gormStatusRepository) FindByText(ctx context.Context, text string) (*model.Status, error) {
+	status := model.Status{}
+	if err := r.db.WithContext(ctx).Where("status_text = ?", text).First(&status).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return &status, nil
+}
+
+func (r *gormStatusRepository) FindByIDs(ctx context.Context, ids []int) ([]model.Status, error) {
+	status := []model.Status{}
+	if len(ids) == 0 {
+		return status, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&status).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return status, nil
+}
+
+func (r *gormStatusRepository) ListCursor(ctx context.Context, page lib.CursorPagination) ([]model.Status, error) {
+	status := []model.Status{}
+	if err := r.db.WithContext(ctx).Model(&model.Status{}).Scopes(services.CursorPaginate(page)).Find(&status).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return status, nil
+}
+
+func (r *gormStatusRepository) Update(ctx context.Context, status *model.Status) error {
+	return services.TranslateError(replaceVersion(r.db.WithContext(ctx), status, &status.Base))
+}
+
+func (r *gormStatusRepository) Delete(ctx context.Context, id int, version int, reassignTo int) error {
+	return services.TranslateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		status := model.Status{}
+		if err := tx.Where("id = ?", id).First(&status).Error; err != nil {
+			return err
+		}
+		if version != 0 && status.Version != version {
+			return &services.DBError{Kind: services.ErrSerializationFailure}
+		}
+
+		var used int64
+		if err := tx.Model(&model.Todo{}).Where("status_id = ?", status.ID).Count(&used).Error; err != nil {
+			return err
+		}
+		if used > 0 {
+			if reassignTo == 0 {
+				return &StatusInUseError{Used: used}
+			}
+			target := model.Status{}
+			if result := tx.Where("id = ?", reassignTo).Limit(1).Find(&target); result.Error != nil {
+				return result.Error
+			} else if result.RowsAffected < 1 || target.ID == status.ID {
+				return ErrInvalidReassign
+			}
+			if err := tx.Model(&model.Todo{}).Where("status_id = ?", status.ID).
+				Updates(map[string]interface{}{"status_id": target.ID, "version": gorm.Expr("version + 1")}).Error; err != nil {
+				return err
+			}
+		}
+
+		// the status may be updated concurrently since it was read
+		return deleteVersion(tx, &model.Status{}, status.ID, status.Version)
+	}))
+}
+
+func (r *gormStatusRepository) Allowed(ctx context.Context, status *model.Status) ([]model.Status, error) {
+	allowed := []model.Status{}
+	if status.IsTerminal != nil && *status.IsTerminal {
+		return allowed, nil
+	}
+
+	db := r.db.WithContext(ctx)
+	var edges int64
+	if err := db.Model(&model.StatusTransition{}).Where("from_status_id = ?", status.ID).Count(&edges).Error; err != nil {
+		return nil, services.TranslateError(err)
+	}
+
+	query := db.Model(&model.Status{}).Where("id <> ?", status.ID).Order("position asc").Order("id asc")
+	if edges > 0 {
+		query = query.Where("id IN (?)", db.Model(&model.StatusTransition{}).Select("to_status_id").Where("from_status_id = ?", status.ID))
+	}
+	if err := query.Find(&allowed).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return allowed, nil
+}
+
+func (r *gormStatusRepository) ReplaceTransitions(ctx context.Context, id int, toStatusIDs []int) error {
+	return services.TranslateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("from_status_id = ?", id).Delete(&model.StatusTransition{}).Error; err != nil {
+			return err
+		}
+		for _, toID := range toStatusIDs {
+			if err := tx.Create(&model.StatusTransition{FromStatusID: id, ToStatusID: toID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (r *gormStatusRepository) AddTransitions(ctx context.Context, id int, toStatusIDs []int) error {
+	return services.TranslateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, toID := range toStatusIDs {
+			transition := model.StatusTransition{FromStatusID: id, ToStatusID: toID}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&transition).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// NewRepositories repositories stored in the database
+func NewRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Todos:  NewTodoRepository(db),
+		Users:  NewUserRepository(db),
+		Status: NewStatusRepository(db),
+		Trash:  NewTrashRepository(db),
+		Tokens: NewTokenRepository(db),
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormTodoRepository TodoRepository stored with GORM
+type gormTodoRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoRepository TodoRepository stored in the database
+func NewTodoRepository(db *gorm.DB) TodoRepository {
+	return &gormTodoRepository{db: db}
+}
+
+func (r *gormTodoRepository) Create(ctx context.Context, todo *model.Todo) error {
+	db := r.db.WithContext(ctx)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(todo).Error; err != nil {
+			return err
+		}
+		return tx.Model(todo).Association("Assignees").Replace(todo.Assignees)
+	})
+	if nil != err {
+		return services.TranslateError(err)
+	}
+	return services.TranslateError(db.Scopes(PreloadTodo).First(todo, todo.ID).Error)
+}
+
+func (r *gormTodoRepository) Find(ctx context.Context, id int) (*model.Todo, error) {
+	todo := model.Todo{}
+	if err := r.db.WithContext(ctx).Scopes(PreloadTodo).Where("id = ?", id).First(&todo).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return &todo, nil
+}
+
+func (r *gormTodoRepository) List(ctx context.Context, filter TodoFilter, page lib.Pagination) ([]model.Todo, int64, error) {
+	query := r.filter(r.db.WithContext(ctx).Model(&model.Todo{}), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; nil != err {
+		return nil, 0, services.TranslateError(err)
+	}
+
+	todos := []model.Todo{}
+	if err := query.Scopes(PreloadTodo, services.Paginate(page)).Find(&todos).Error; nil != err {
+		return nil, 0, services.TranslateError(err)
+	}
+	return todos, total, nil
+}
+
+func (r *gormTodoRepository) ListCursor(ctx context.Context, filter TodoFilter, page lib.CursorPagination) ([]model.Todo, error) {
+	todos := []model.Todo{}
+	query := r.filter(r.db.WithContext(ctx).Model(&model.Todo{}), filter)
+	if err := query.Scopes(PreloadTodo, services.CursorPaginate(page)).Find(&todos).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return todos, nil
+}
+
+func (r *gormTodoRepository) Update(ctx context.Context, todo *model.Todo) error {
+	db := r.db.WithContext(ctx)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := replaceVersion(tx, todo, &todo.Base); err != nil {
+			return err
+		}
+		// the preloaded assignees skip the users in the trash, their assignment is kept
+		trashedUsers := tx.Unscoped().Model(&model.User{}).Select("id").Where("deleted_at IS NOT NULL")
+		if err := tx.Exec("DELETE FROM todo_assignee WHERE todo_id = ? AND user_id NOT IN (?)", todo.ID, trashedUsers).Error; err != nil {
+			return err
+		}
+		for _, id := range userIDs(todo.Assignees) {
+			if err := tx.Exec("INSERT INTO todo_assignee (todo_id, user_id) VALUES (?, ?)", todo.ID, id).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if nil != err {
+		return services.TranslateError(err)
+	}
+	return services.TranslateError(db.Scopes(PreloadTodo).First(todo, todo.ID).Error)
+}
+
+func (r *gormTodoRepository) Delete(ctx context.Context, id int, version int) error {
+	return services.TranslateError(deleteVersion(r.db.WithContext(ctx), &model.Todo{}, id, version))
+}
+
+// filter apply the todo filter to the query
+func (r *gormTodoRepository) filter(query *gorm.DB, filter TodoFilter) *gorm.DB {
+	if nil != filter.StatusID {
+		query = query.Where("status_id = ?", *filter.StatusID)
+	}
+	if nil != filter.StatusText {
+		query = query.Where("status_id IN (?)", r.db.Model(&model.Status{}).Select("id").Where("status_text = ?", *filter.StatusText))
+	}
+	if nil != filter.AssigneeID {
+		query = query.Scopes(AssignedTo(*filter.AssigneeID))
+	}
+	if nil != filter.DueDateFrom {
+		query = query.Where("due_date >= ?", *filter.DueDateFrom)
+	}
+	if nil != filter.DueDateTo {
+		query = query.Where("due_date <= ?", *filter.DueDateTo)
+	}
+	return query
+}
+
+// PreloadTodo scope to load the todo relations
+func PreloadTodo(db *gorm.DB) *gorm.DB {
+	return db.Preload("Status").Preload("Assignees")
+}
+
+// AssignedTo scope to filter todo assigned to the user
+func AssignedTo(userID int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("todo.id IN (?)", db.Session(&gorm.Session{NewDB: true}).Table("todo_assignee").Select("todo_id").Where("user_id = ?", userID))
+	}
+}
+
+// replaceVersion replace every column of the record still at its version and increment the version
+func replaceVersion(tx *gorm.DB, value interface{}, base *model.Base) error {
+	version := base.Version
+	base.Version++
+	result := tx.Select("*").Omit(clause.Associations).Where("version = ?", version).Updates(value)
+	if nil == result.Error && result.RowsAffected < 1 {
+		result.Error = &services.DBError{Kind: services.ErrSerializationFailure}
+	}
+	if nil != result.Error {
+		base.Version = version
+	}
+	return result.Error
+}
+
+func (r *gormTodoRepository) AssigneeIDs(ctx context.Context, id int) ([]int, error) {
+	ids := []int{}
+	if err := r.db.WithContext(ctx).Table("todo_assignee").Where("todo_id = ?", id).Order("user_id asc").Pluck("user_id", &ids).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return ids, nil
+}
+
+// deleteVersion delete the record by id still at its version, any version when it is 0
+func deleteVersion(tx *gorm.DB, value interface{}, id int, version int) error {
+	if version != 0 {
+		tx = tx.Where("version = ?", version)
+	}
+	result := tx.Delete(value, id)
+	if nil != result.Error {
+		return result.Error
+	}
+	if result.RowsAffected < 1 {
+		if version != 0 {
+			return &services.DBError{Kind: services.ErrSerializationFailure}
+		}
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
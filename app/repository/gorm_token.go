@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+)
+
+// gormTokenRepository TokenRepository stored with GORM
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewTokenRepository TokenRepository stored in the database
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &gormTokenRepository{db: db}
+}
+
+func (r *gormTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	return services.TranslateError(r.db.WithContext(ctx).Create(token).Error)
+}
+
+func (r *gormTokenRepository) Revoke(ctx context.Context, tokenID string) (*model.RefreshToken, error) {
+	db := r.db.WithContext(ctx)
+	stored := model.RefreshToken{}
+	if result := db.Where("token_id = ? AND revoked_at IS NULL", tokenID).Limit(1).Find(&stored); nil != result.Error {
+		return nil, services.TranslateError(result.Error)
+	} else if result.RowsAffected < 1 {
+		return nil, services.ErrInvalidToken
+	}
+
+	result := db.Model(&stored).Where("revoked_at IS NULL").Update("revoked_at", time.Now())
+	if nil != result.Error {
+		return nil, services.TranslateError(result.Error)
+	}
+	if result.RowsAffected < 1 {
+		// revoked by a concurrent request
+		return nil, services.ErrInvalidToken
+	}
+	return &stored, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+)
+
+// gormTrashRepository TrashRepository of the data soft deleted with GORM
+type gormTrashRepository struct {
+	db *gorm.DB
+}
+
+// NewTrashRepository TrashRepository stored in the database
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &gormTrashRepository{db: db}
+}
+
+// trashModel model and list of the trash resource
+func trashModel(resource string) (interface{}, interface{}, bool) {
+	switch resource {
+	case "todos":
+		return &model.Todo{}, &[]model.Todo{}, true
+	case "users":
+		return &model.User{}, &[]model.User{}, true
+	case "status":
+		return &model.Status{}, &[]model.Status{}, true
+	}
+	return nil, nil, false
+}
+
+// trashed scope to select only the soft deleted data
+func trashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+func (r *gormTrashRepository) ListCursor(ctx context.Context, resource string, page lib.CursorPagination) (interface{}, error) {
+	value, items, ok := trashModel(resource)
+	if !ok {
+		return nil, notFound()
+	}
+
+	query := r.db.WithContext(ctx).Model(value).Scopes(trashed, services.CursorPaginate(page))
+	if _, ok := value.(*model.Todo); ok {
+		query = query.Scopes(PreloadTodo)
+	}
+	if err := query.Find(items).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return items, nil
+}
+
+func (r *gormTrashRepository) Restore(ctx context.Context, resource string, id int) (interface{}, error) {
+	value, _, ok := trashModel(resource)
+	if !ok {
+		return nil, notFound()
+	}
+
+	db := r.db.WithContext(ctx)
+	if err := db.Scopes(trashed).Where("id = ?", id).First(value).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	if _, ok := value.(*model.Todo); ok {
+		if trashed, err := trashedDependencies(db, id); nil != err {
+			return nil, services.TranslateError(err)
+		} else if trashed {
+			return nil, ErrTrashedDependency
+		}
+	}
+
+	if err := db.Unscoped().Model(value).Update("deleted_at", nil).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	if todo, ok := value.(*model.Todo); ok {
+		if err := db.Scopes(PreloadTodo).First(todo, id).Error; nil != err {
+			return nil, services.TranslateError(err)
+		}
+	}
+	return value, nil
+}
+
+// trashedDependencies check whether the status or any assignee of the todo is in the trash
+func trashedDependencies(db *gorm.DB, id int) (bool, error) {
+	var count int64
+	status := db.Unscoped().Model(&model.Todo{}).Select("status_id").Where("id = ?", id)
+	if err := db.Model(&model.Status{}).Scopes(trashed).Where("id IN (?)", status).Count(&count).Error; nil != err || count > 0 {
+		return count > 0, err
+	}
+	assignees := db.Table("todo_assignee").Select("user_id").Where("todo_id = ?", id)
+	if err := db.Model(&model.User{}).Scopes(trashed).Where("id IN (?)", assignees).Count(&count).Error; nil != err {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormTrashRepository) Purge(ctx context.Context, resource string, id int) error {
+	value, _, ok := trashModel(resource)
+	if !ok {
+		return notFound()
+	}
+
+	db := r.db.WithContext(ctx)
+	if err := db.Scopes(trashed).Where("id = ?", id).First(value).Error; nil != err {
+		return services.TranslateError(err)
+	}
+	if err := services.Purge(db, value); err == services.ErrStatusInUse {
+		return err
+	} else if nil != err {
+		return services.TranslateError(err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+)
+
+// gormUserRepository UserRepository stored with GORM
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository UserRepository stored in the database
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, user *model.User) error {
+	return services.TranslateError(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *gormUserRepository) Find(ctx context.Context, id int) (*model.User, error) {
+	user := model.User{}
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindWithTrashed(ctx context.Context, id int) (*model.User, error) {
+	user := model.User{}
+	if err := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).First(&user).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	user := model.User{}
+	if err := r.db.WithContext(ctx).Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) FindByIDs(ctx context.Context, ids []int) ([]model.User, error) {
+	users := []model.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return users, nil
+}
+
+func (r *gormUserRepository) ListCursor(ctx context.Context, filter UserFilter, page lib.CursorPagination) ([]model.User, error) {
+	users := []model.User{}
+	query := r.db.WithContext(ctx).Model(&model.User{})
+	if nil != filter.Name {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(*filter.Name))+"%")
+	}
+	if nil != filter.NamePrefix {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, escapeLike(strings.ToLower(*filter.NamePrefix))+"%")
+	}
+	if err := query.Scopes(services.CursorPaginate(page)).Find(&users).Error; nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return users, nil
+}
+
+func (r *gormUserRepository) Update(ctx context.Context, user *model.User) error {
+	return services.TranslateError(replaceVersion(r.db.WithContext(ctx), user, &user.Base))
+}
+
+func (r *gormUserRepository) Delete(ctx context.Context, id int, version int) error {
+	return services.TranslateError(deleteVersion(r.db.WithContext(ctx), &model.User{}, id, version))
+}
+
+func (r *gormUserRepository) Stats(ctx context.Context, id int) (*UserStats, error) {
+	db := r.db.WithContext(ctx)
+	rows := []struct {
+		StatusText *string
+		IsTerminal *bool
+		Total      int64
+	}{}
+	err := db.Model(&model.Todo{}).Scopes(AssignedTo(id)).
+		Select("status.status_text, status.is_terminal, count(*) AS total").
+		Joins("LEFT JOIN status ON status.id = todo.status_id").
+		Group("status.status_text, status.is_terminal").
+		Scan(&rows).Error
+	if nil != err {
+		return nil, services.TranslateError(err)
+	}
+
+	stats := UserStats{TodoByStatus: map[string]int64{}}
+	for _, row := range rows {
+		status := ""
+		if nil != row.StatusText {
+			status = *row.StatusText
+		}
+		stats.TodoTotal += row.Total
+		stats.TodoByStatus[status] += row.Total
+		if nil != row.IsTerminal && *row.IsTerminal {
+			stats.TodoDone += row.Total
+		}
+	}
+
+	err = db.Model(&model.Todo{}).Scopes(AssignedTo(id)).
+		Joins("LEFT JOIN status ON status.id = todo.status_id").
+		Where("todo.due_date < ?", time.Now().Format("2006-01-02")).
+		Where("status.is_terminal IS NULL OR status.is_terminal = ?", false).
+		Count(&stats.TodoOverdue).Error
+	if nil != err {
+		return nil, services.TranslateError(err)
+	}
+	return &stats, nil
+}
+
+// escapeLike escape the LIKE wildcards of the search text, used with ESCAPE '\'
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
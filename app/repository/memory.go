@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+)
+
+// memoryStore data shared by the in-memory repositories, deleted data is moved to the trash maps
+type memoryStore struct {
+	mu            sync.RWMutex
+	todos         map[int]model.Todo
+	users         map[int]model.User
+	status        map[int]model.Status
+	assignees     map[int][]int // user ids by todo id, kept while the todo or user is in the trash
+	transitions   map[int][]int // next status ids by status id
+	trashedTodos  map[int]model.Todo
+	trashedUsers  map[int]model.User
+	trashedStatus map[int]model.Status
+	tokens        map[string]model.RefreshToken // refresh token by token id
+	sequence      int
+}
+
+// NewMemoryRepositories repositories kept in memory, used by the handler tests
+func NewMemoryRepositories() Repositories {
+	store := &memoryStore{
+		todos:         map[int]model.Todo{},
+		users:         map[int]model.User{},
+		status:        map[int]model.Status{},
+		assignees:     map[int][]int{},
+		transitions:   map[int][]int{},
+		trashedTodos:  map[int]model.Todo{},
+		trashedUsers:  map[int]model.User{},
+		trashedStatus: map[int]model.Status{},
+		tokens:        map[string]model.RefreshToken{},
+	}
+	return Repositories{
+		Todos:  &memoryTodoRepository{store},
+		Users:  &memoryUserRepository{store},
+		Status: &memoryStatusRepository{store},
+		Trash:  &memoryTrashRepository{store},
+		Tokens: &memoryTokenRepository{store},
+	}
+}
+
+// create fill the base of the new data
+func (s *memoryStore) create(ctx context.Context, base *model.Base) {
+	s.sequence++
+	now := time.Now()
+	actor := lib.ActorFromContext(ctx)
+	base.ID = s.sequence
+	base.CreatedAt = now
+	base.UpdatedAt = now
+	base.CreatedBy = actor
+	base.UpdatedBy = actor
+	base.Version = 1
+}
+
+// update fill the base of the updated data from the stored base, the data must be at the stored version
+func (s *memoryStore) update(ctx context.Context, base *model.Base, stored model.Base) error {
+	if base.Version != stored.Version {
+		return &services.DBError{Kind: services.ErrSerializationFailure}
+	}
+	base.Version = stored.Version + 1
+	base.CreatedAt = stored.CreatedAt
+	base.CreatedBy = stored.CreatedBy
+	base.UpdatedAt = time.Now()
+	base.UpdatedBy = lib.ActorFromContext(ctx)
+	return nil
+}
+
+// deleteVersion check the stored data is at the version to delete, any version when it is 0
+func (s *memoryStore) deleteVersion(stored model.Base, version int) error {
+	if version != 0 && stored.Version != version {
+		return &services.DBError{Kind: services.ErrSerializationFailure}
+	}
+	return nil
+}
+
+// softDelete mark the data deleted now, the caller move it to the trash
+func (s *memoryStore) softDelete(base *model.Base) {
+	base.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+}
+
+// load copy of the todo with its status and assignees
+func (s *memoryStore) load(todo model.Todo) model.Todo {
+	todo = cloneTodo(todo)
+	todo.Status = nil
+	if nil != todo.StatusID {
+		if status, ok := s.status[*todo.StatusID]; ok {
+			status = cloneStatus(status)
+			todo.Status = &status
+		}
+	}
+	todo.Assignees = []model.User{}
+	for _, id := range s.assignees[todo.ID] {
+		if user, ok := s.users[id]; ok {
+			todo.Assignees = append(todo.Assignees, cloneUser(user))
+		}
+	}
+	return todo
+}
+
+// cloneTodo copy of the todo not sharing the field values,
+// the caller may decode the request body into the returned data
+func cloneTodo(todo model.Todo) model.Todo {
+	todo.Base = cloneBase(todo.Base)
+	todo.Title = cloneString(todo.Title)
+	todo.Description = cloneString(todo.Description)
+	todo.DueDate = cloneString(todo.DueDate)
+	todo.StatusID = cloneInt(todo.StatusID)
+	return todo
+}
+
+// cloneUser copy of the user not sharing the field values
+func cloneUser(user model.User) model.User {
+	user.Base = cloneBase(user.Base)
+	user.Name = cloneString(user.Name)
+	user.Email = cloneString(user.Email)
+	user.PasswordHash = cloneString(user.PasswordHash)
+	return user
+}
+
+// cloneStatus copy of the status not sharing the field values
+func cloneStatus(status model.Status) model.Status {
+	status.Base = cloneBase(status.Base)
+	status.StatusText = cloneString(status.StatusText)
+	status.Position = cloneInt(status.Position)
+	status.Color = cloneString(status.Color)
+	if nil != status.IsTerminal {
+		terminal := *status.IsTerminal
+		status.IsTerminal = &terminal
+	}
+	return status
+}
+
+func cloneBase(base model.Base) model.Base {
+	base.CreatedBy = cloneString(base.CreatedBy)
+	base.UpdatedBy = cloneString(base.UpdatedBy)
+	return base
+}
+
+func cloneString(value *string) *string {
+	if nil == value {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+func cloneInt(value *int) *int {
+	if nil == value {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// match check whether the todo pass the filter
+func (s *memoryStore) match(todo model.Todo, filter TodoFilter) bool {
+	if nil != filter.StatusID && (nil == todo.StatusID || *todo.StatusID != *filter.StatusID) {
+		return false
+	}
+	if nil != filter.StatusText {
+		status, ok := model.Status{}, false
+		if nil != todo.StatusID {
+			status, ok = s.status[*todo.StatusID]
+		}
+		if !ok || nil == status.StatusText || *status.StatusText != *filter.StatusText {
+			return false
+		}
+	}
+	if nil != filter.AssigneeID && !containsInt(s.assignees[todo.ID], *filter.AssigneeID) {
+		return false
+	}
+	if nil != filter.DueDateFrom && (nil == todo.DueDate || *todo.DueDate < *filter.DueDateFrom) {
+		return false
+	}
+	if nil != filter.DueDateTo && (nil == todo.DueDate || *todo.DueDate > *filter.DueDateTo) {
+		return false
+	}
+	return true
+}
+
+func notFound() error {
+	return &services.DBError{Kind: services.ErrNotFound}
+}
+
+func duplicate(constraint string) error {
+	return &services.DBError{Kind: services.ErrDuplicate, Constraint: constraint}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// compareString compare the optional values, nil is the smallest
+func compareString(a, b *string) int {
+	switch {
+	case nil == a && nil == b:
+		return 0
+	case nil == a:
+		return -1
+	case nil == b:
+		return 1
+	}
+	return strings.Compare(*a, *b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// compareCursor compare the (updated_at, id) ordering
+func compareCursor(a, b lib.CursorKeyer) int {
+	aTime, aID := a.CursorKey()
+	bTime, bID := b.CursorKey()
+	if result := compareTime(aTime, bTime); result != 0 {
+		return result
+	}
+	return compareInt(aID, bID)
+}
+
+// cursorWindow index of the items of the cursor page from the items sorted by (updated_at, id),
+// one more item than the limit is kept to detect whether more items exist
+func cursorWindow(keys []lib.CursorKeyer, page lib.CursorPagination) []int {
+	indexes := []int{}
+	for i, key := range keys {
+		if nil == page.Cursor {
+			indexes = append(indexes, i)
+			continue
+		}
+		result := compareCursor(key, model.Base{UpdatedAt: page.Cursor.UpdatedAt, ID: page.Cursor.ID})
+		if (page.Cursor.Backward && result < 0) || (!page.Cursor.Backward && result > 0) {
+			indexes = append(indexes, i)
+		}
+	}
+	if nil != page.Cursor && page.Cursor.Backward {
+		for i, j := 0, len(indexes)-1; i < j; i, j = i+1, j-1 {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		}
+	}
+	if len(indexes) > page.Limit+1 {
+		indexes = indexes[:page.Limit+1]
+	}
+	return indexes
+}
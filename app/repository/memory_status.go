@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+)
+
+// memoryStatusRepository StatusRepository kept in memory
+type memoryStatusRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryStatusRepository) Create(ctx context.Context, status *model.Status) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.textTaken(status.StatusText, 0) {
+		return duplicate("idx_status_status_text")
+	}
+	r.store.create(ctx, &status.Base)
+	r.store.status[status.ID] = cloneStatus(*status)
+	return nil
+}
+
+func (r *memoryStatusRepository) Find(ctx context.Context, id int) (*model.Status, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	status, ok := r.store.status[id]
+	if !ok {
+		return nil, notFound()
+	}
+	status = cloneStatus(status)
+	return &status, nil
+}
+
+func (r *memoryStatusRepository) FindByText(ctx context.Context, text string) (*model.Status, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, status := range r.sorted() {
+		if nil != status.StatusText && *status.StatusText == text {
+			status = cloneStatus(status)
+			return &status, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (r *memoryStatusRepository) FindByIDs(ctx context.Context, ids []int) ([]model.Status, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	status := []model.Status{}
+	for _, item := range r.sorted() {
+		if containsInt(ids, item.ID) {
+			status = append(status, item)
+		}
+	}
+	return status, nil
+}
+
+func (r *memoryStatusRepository) ListCursor(ctx context.Context, page lib.CursorPagination) ([]model.Status, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	status := r.sorted()
+	sort.SliceStable(status, func(i, j int) bool {
+		return compareCursor(status[i], status[j]) < 0
+	})
+	keys := make([]lib.CursorKeyer, len(status))
+	for i := range status {
+		keys[i] = status[i]
+	}
+
+	window := []model.Status{}
+	for _, i := range cursorWindow(keys, page) {
+		window = append(window, status[i])
+	}
+	return window, nil
+}
+
+func (r *memoryStatusRepository) Update(ctx context.Context, status *model.Status) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.status[status.ID]
+	if !ok {
+		return notFound()
+	}
+	if r.textTaken(status.StatusText, status.ID) {
+		return duplicate("idx_status_status_text")
+	}
+	if err := r.store.update(ctx, &status.Base, stored.Base); nil != err {
+		return err
+	}
+	r.store.status[status.ID] = cloneStatus(*status)
+	return nil
+}
+
+func (r *memoryStatusRepository) Delete(ctx context.Context, id int, version int, reassignTo int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	status, ok := r.store.status[id]
+	if !ok {
+		return notFound()
+	}
+	if err := r.store.deleteVersion(status.Base, version); nil != err {
+		return err
+	}
+
+	used := []int{}
+	for _, todo := range r.store.todos {
+		if nil != todo.StatusID && *todo.StatusID == id {
+			used = append(used, todo.ID)
+		}
+	}
+	if len(used) > 0 {
+		if reassignTo == 0 {
+			return &StatusInUseError{Used: int64(len(used))}
+		}
+		if _, ok := r.store.status[reassignTo]; !ok || reassignTo == id {
+			return ErrInvalidReassign
+		}
+		for _, todoID := range used {
+			todo := r.store.todos[todoID]
+			statusID := reassignTo
+			todo.StatusID = &statusID
+			todo.Version++
+			r.store.todos[todoID] = todo
+		}
+	}
+
+	r.store.softDelete(&status.Base)
+	r.store.trashedStatus[id] = status
+	delete(r.store.status, id)
+	return nil
+}
+
+func (r *memoryStatusRepository) Allowed(ctx context.Context, status *model.Status) ([]model.Status, error) {
+	allowed := []model.Status{}
+	if status.IsTerminal != nil && *status.IsTerminal {
+		return allowed, nil
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	next := r.store.transitions[status.ID]
+	for _, item := range r.sorted() {
+		if item.ID != status.ID && (len(next) == 0 || containsInt(next, item.ID)) {
+			allowed = append(allowed, item)
+		}
+	}
+	sort.SliceStable(allowed, func(i, j int) bool {
+		a, b := allowed[i].Position, allowed[j].Position
+		if nil == a || nil == b {
+			return nil != a
+		}
+		return *a < *b
+	})
+	return allowed, nil
+}
+
+func (r *memoryStatusRepository) ReplaceTransitions(ctx context.Context, id int, toStatusIDs []int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, toID := range toStatusIDs {
+		if _, ok := r.store.status[toID]; !ok {
+			return &services.DBError{Kind: services.ErrForeignKeyViolation}
+		}
+	}
+	r.store.transitions[id] = append([]int{}, toStatusIDs...)
+	return nil
+}
+
+func (r *memoryStatusRepository) AddTransitions(ctx context.Context, id int, toStatusIDs []int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, toID := range toStatusIDs {
+		if _, ok := r.store.status[toID]; !ok {
+			return &services.DBError{Kind: services.ErrForeignKeyViolation}
+		}
+	}
+	for _, toID := range toStatusIDs {
+		if !containsInt(r.store.transitions[id], toID) {
+			r.store.transitions[id] = append(r.store.transitions[id], toID)
+		}
+	}
+	return nil
+}
+
+// sorted stored status ordered by id
+func (r *memoryStatusRepository) sorted() []model.Status {
+	status := []model.Status{}
+	for _, item := range r.store.status {
+		status = append(status, item)
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].ID < status[j].ID
+	})
+	return status
+}
+
+// textTaken check whether another status, in the trash too, has the status text
+func (r *memoryStatusRepository) textTaken(text *string, id int) bool {
+	if nil == text {
+		return false
+	}
+	for _, status := range []map[int]model.Status{r.store.status, r.store.trashedStatus} {
+		for _, stored := range status {
+			if stored.ID != id && nil != stored.StatusText && *stored.StatusText == *text {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+)
+
+// memoryTodoRepository TodoRepository kept in memory
+type memoryTodoRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTodoRepository) Create(ctx context.Context, todo *model.Todo) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.create(ctx, &todo.Base)
+	r.store.todos[todo.ID] = r.record(*todo)
+	r.store.assignees[todo.ID] = userIDs(todo.Assignees)
+	*todo = r.store.load(r.store.todos[todo.ID])
+	return nil
+}
+
+func (r *memoryTodoRepository) Find(ctx context.Context, id int) (*model.Todo, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stored, ok := r.store.todos[id]
+	if !ok {
+		return nil, notFound()
+	}
+	todo := r.store.load(stored)
+	return &todo, nil
+}
+
+func (r *memoryTodoRepository) List(ctx context.Context, filter TodoFilter, page lib.Pagination) ([]model.Todo, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todos := r.filter(filter)
+	sort.SliceStable(todos, func(i, j int) bool {
+		for _, clause := range append(page.Sort, "id asc") {
+			column, direction := clause, "asc"
+			if fields := strings.Fields(clause); len(fields) == 2 {
+				column, direction = fields[0], fields[1]
+			}
+			result := compareTodo(todos[i], todos[j], column)
+			if direction == "desc" {
+				result = -result
+			}
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+
+	total := int64(len(todos))
+	start, end := page.Offset(), page.Offset()+page.Limit
+	if start > len(todos) {
+		start = len(todos)
+	}
+	if end > len(todos) {
+		end = len(todos)
+	}
+	return r.load(todos[start:end]), total, nil
+}
+
+func (r *memoryTodoRepository) ListCursor(ctx context.Context, filter TodoFilter, page lib.CursorPagination) ([]model.Todo, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	todos := r.filter(filter)
+	sort.Slice(todos, func(i, j int) bool {
+		return compareCursor(todos[i], todos[j]) < 0
+	})
+	keys := make([]lib.CursorKeyer, len(todos))
+	for i := range todos {
+		keys[i] = todos[i]
+	}
+
+	window := []model.Todo{}
+	for _, i := range cursorWindow(keys, page) {
+		window = append(window, todos[i])
+	}
+	return r.load(window), nil
+}
+
+func (r *memoryTodoRepository) Update(ctx context.Context, todo *model.Todo) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.todos[todo.ID]
+	if !ok {
+		return notFound()
+	}
+	if err := r.store.update(ctx, &todo.Base, stored.Base); nil != err {
+		return err
+	}
+	assignees := userIDs(todo.Assignees)
+	for _, id := range r.store.assignees[todo.ID] {
+		if _, trashed := r.store.trashedUsers[id]; trashed && !containsInt(assignees, id) {
+			assignees = append(assignees, id)
+		}
+	}
+	r.store.todos[todo.ID] = r.record(*todo)
+	r.store.assignees[todo.ID] = assignees
+	*todo = r.store.load(r.store.todos[todo.ID])
+	return nil
+}
+
+func (r *memoryTodoRepository) AssigneeIDs(ctx context.Context, id int) ([]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.todos[id]; !ok {
+		return nil, notFound()
+	}
+	ids := append([]int{}, r.store.assignees[id]...)
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (r *memoryTodoRepository) Delete(ctx context.Context, id int, version int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	todo, ok := r.store.todos[id]
+	if !ok {
+		return notFound()
+	}
+	if err := r.store.deleteVersion(todo.Base, version); nil != err {
+		return err
+	}
+	r.store.softDelete(&todo.Base)
+	r.store.trashedTodos[id] = todo
+	delete(r.store.todos, id)
+	return nil
+}
+
+// record stored copy of the todo without the relations
+func (r *memoryTodoRepository) record(todo model.Todo) model.Todo {
+	todo = cloneTodo(todo)
+	todo.StatusText = nil
+	todo.AssigneeIDs = nil
+	todo.Status = nil
+	todo.Assignees = nil
+	return todo
+}
+
+// filter stored todo passing the filter
+func (r *memoryTodoRepository) filter(filter TodoFilter) []model.Todo {
+	todos := []model.Todo{}
+	for _, todo := range r.store.todos {
+		if r.store.match(todo, filter) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos
+}
+
+// load fill the relations of the todo
+func (r *memoryTodoRepository) load(todos []model.Todo) []model.Todo {
+	loaded := make([]model.Todo, len(todos))
+	for i, todo := range todos {
+		loaded[i] = r.store.load(todo)
+	}
+	return loaded
+}
+
+// compareTodo compare the todo by the sortable column
+func compareTodo(a, b model.Todo, column string) int {
+	switch column {
+	case "title":
+		return compareString(a.Title, b.Title)
+	case "due_date":
+		return compareString(a.DueDate, b.DueDate)
+	case "status_id":
+		aID, bID := 0, 0
+		if nil != a.StatusID {
+			aID = *a.StatusID
+		}
+		if nil != b.StatusID {
+			bID = *b.StatusID
+		}
+		return compareInt(aID, bID)
+	case "created_at":
+		return compareTime(a.CreatedAt, b.CreatedAt)
+	case "updated_at":
+		return compareTime(a.UpdatedAt, b.UpdatedAt)
+	}
+	return compareInt(a.ID, b.ID)
+}
+
+func userIDs(users []model.User) []int {
+	ids := []int{}
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
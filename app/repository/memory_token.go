@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+)
+
+// memoryTokenRepository TokenRepository kept in memory
+type memoryTokenRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tokens[token.TokenID]; ok {
+		return duplicate("idx_refresh_token_token_id")
+	}
+	r.store.create(ctx, &token.Base)
+	r.store.tokens[token.TokenID] = *token
+	return nil
+}
+
+func (r *memoryTokenRepository) Revoke(ctx context.Context, tokenID string) (*model.RefreshToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.tokens[tokenID]
+	if !ok || nil != stored.RevokedAt {
+		return nil, services.ErrInvalidToken
+	}
+	now := time.Now()
+	stored.RevokedAt = &now
+	r.store.tokens[tokenID] = stored
+	return &stored, nil
+}
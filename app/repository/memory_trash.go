@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+)
+
+// memoryTrashRepository TrashRepository of the data moved to the trash maps
+type memoryTrashRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTrashRepository) ListCursor(ctx context.Context, resource string, page lib.CursorPagination) (interface{}, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := []lib.CursorKeyer{}
+	switch resource {
+	case "todos":
+		for _, todo := range r.store.trashedTodos {
+			keys = append(keys, r.store.load(todo))
+		}
+	case "users":
+		for _, user := range r.store.trashedUsers {
+			keys = append(keys, cloneUser(user))
+		}
+	case "status":
+		for _, status := range r.store.trashedStatus {
+			keys = append(keys, cloneStatus(status))
+		}
+	default:
+		return nil, notFound()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return compareCursor(keys[i], keys[j]) < 0
+	})
+
+	todos, users, status := []model.Todo{}, []model.User{}, []model.Status{}
+	for _, i := range cursorWindow(keys, page) {
+		switch data := keys[i].(type) {
+		case model.Todo:
+			todos = append(todos, data)
+		case model.User:
+			users = append(users, data)
+		case model.Status:
+			status = append(status, data)
+		}
+	}
+	switch resource {
+	case "todos":
+		return &todos, nil
+	case "users":
+		return &users, nil
+	}
+	return &status, nil
+}
+
+func (r *memoryTrashRepository) Restore(ctx context.Context, resource string, id int) (interface{}, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	switch resource {
+	case "todos":
+		todo, ok := r.store.trashedTodos[id]
+		if !ok {
+			return nil, notFound()
+		}
+		if r.trashedDependencies(todo) {
+			return nil, ErrTrashedDependency
+		}
+		todo.DeletedAt = gorm.DeletedAt{}
+		r.store.todos[id] = todo
+		delete(r.store.trashedTodos, id)
+		restored := r.store.load(todo)
+		return &restored, nil
+	case "users":
+		user, ok := r.store.trashedUsers[id]
+		if !ok {
+			return nil, notFound()
+		}
+		if (&memoryUserRepository{r.store}).emailTaken(user.Email, id) {
+			return nil, duplicate("idx_user_email")
+		}
+		user.DeletedAt = gorm.DeletedAt{}
+		r.store.users[id] = user
+		delete(r.store.trashedUsers, id)
+		restored := cloneUser(user)
+		return &restored, nil
+	case "status":
+		status, ok := r.store.trashedStatus[id]
+		if !ok {
+			return nil, notFound()
+		}
+		status.DeletedAt = gorm.DeletedAt{}
+		r.store.status[id] = status
+		delete(r.store.trashedStatus, id)
+		restored := cloneStatus(status)
+		return &restored, nil
+	}
+	return nil, notFound()
+}
+
+// trashedDependencies check whether the status or any assignee of the todo is in the trash
+func (r *memoryTrashRepository) trashedDependencies(todo model.Todo) bool {
+	if nil != todo.StatusID {
+		if _, ok := r.store.trashedStatus[*todo.StatusID]; ok {
+			return true
+		}
+	}
+	for _, id := range r.store.assignees[todo.ID] {
+		if _, ok := r.store.trashedUsers[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryTrashRepository) Purge(ctx context.Context, resource string, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	switch resource {
+	case "todos":
+		if _, ok := r.store.trashedTodos[id]; !ok {
+			return notFound()
+		}
+		delete(r.store.trashedTodos, id)
+		delete(r.store.assignees, id)
+	case "users":
+		if _, ok := r.store.trashedUsers[id]; !ok {
+			return notFound()
+		}
+		delete(r.store.trashedUsers, id)
+		for todoID, ids := range r.store.assignees {
+			kept := []int{}
+			for _, userID := range ids {
+				if userID != id {
+					kept = append(kept, userID)
+				}
+			}
+			r.store.assignees[todoID] = kept
+		}
+	case "status":
+		if _, ok := r.store.trashedStatus[id]; !ok {
+			return notFound()
+		}
+		// the todo in the trash still reference the status
+		for _, todos := range []map[int]model.Todo{r.store.todos, r.store.trashedTodos} {
+			for _, todo := range todos {
+				if nil != todo.StatusID && *todo.StatusID == id {
+					return services.ErrStatusInUse
+				}
+			}
+		}
+		delete(r.store.trashedStatus, id)
+		delete(r.store.transitions, id)
+		for fromID, toIDs := range r.store.transitions {
+			kept := []int{}
+			for _, toID := range toIDs {
+				if toID != id {
+					kept = append(kept, toID)
+				}
+			}
+			r.store.transitions[fromID] = kept
+		}
+	default:
+		return notFound()
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+)
+
+// memoryUserRepository UserRepository kept in memory
+type memoryUserRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	if err := user.BeforeSave(nil); nil != err {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.emailTaken(user.Email, 0) {
+		return duplicate("idx_user_email")
+	}
+	r.store.create(ctx, &user.Base)
+	r.store.users[user.ID] = cloneUser(*user)
+	return nil
+}
+
+func (r *memoryUserRepository) Find(ctx context.Context, id int) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, notFound()
+	}
+	user = cloneUser(user)
+	return &user, nil
+}
+
+func (r *memoryUserRepository) FindWithTrashed(ctx context.Context, id int) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		if user, ok = r.store.trashedUsers[id]; !ok {
+			return nil, notFound()
+		}
+	}
+	user = cloneUser(user)
+	return &user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	for _, user := range r.store.users {
+		if nil != user.Email && *user.Email == email {
+			user = cloneUser(user)
+			return &user, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (r *memoryUserRepository) FindByIDs(ctx context.Context, ids []int) ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := []model.User{}
+	for _, user := range r.store.users {
+		if containsInt(ids, user.ID) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+func (r *memoryUserRepository) ListCursor(ctx context.Context, filter UserFilter, page lib.CursorPagination) ([]model.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := []model.User{}
+	for _, user := range r.store.users {
+		name := ""
+		if nil != user.Name {
+			name = strings.ToLower(*user.Name)
+		}
+		if nil != filter.Name && !strings.Contains(name, strings.ToLower(*filter.Name)) {
+			continue
+		}
+		if nil != filter.NamePrefix && !strings.HasPrefix(name, strings.ToLower(*filter.NamePrefix)) {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return compareCursor(users[i], users[j]) < 0
+	})
+	keys := make([]lib.CursorKeyer, len(users))
+	for i := range users {
+		keys[i] = users[i]
+	}
+
+	window := []model.User{}
+	for _, i := range cursorWindow(keys, page) {
+		window = append(window, users[i])
+	}
+	return window, nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *model.User) error {
+	if err := user.BeforeSave(nil); nil != err {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok {
+		return notFound()
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return duplicate("idx_user_email")
+	}
+	if err := r.store.update(ctx, &user.Base, stored.Base); nil != err {
+		return err
+	}
+	r.store.users[user.ID] = cloneUser(*user)
+	return nil
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id int, version int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return notFound()
+	}
+	if err := r.store.deleteVersion(user.Base, version); nil != err {
+		return err
+	}
+	r.store.softDelete(&user.Base)
+	r.store.trashedUsers[id] = user
+	delete(r.store.users, id)
+	return nil
+}
+
+func (r *memoryUserRepository) Stats(ctx context.Context, id int) (*UserStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stats := UserStats{TodoByStatus: map[string]int64{}}
+	today := time.Now().Format("2006-01-02")
+	for _, todo := range r.store.todos {
+		if !containsInt(r.store.assignees[todo.ID], id) {
+			continue
+		}
+		status, terminal := "", false
+		if nil != todo.StatusID {
+			if stored, ok := r.store.status[*todo.StatusID]; ok {
+				if nil != stored.StatusText {
+					status = *stored.StatusText
+				}
+				terminal = nil != stored.IsTerminal && *stored.IsTerminal
+			}
+		}
+		stats.TodoTotal++
+		stats.TodoByStatus[status]++
+		if terminal {
+			stats.TodoDone++
+		} else if nil != todo.DueDate && *todo.DueDate < today {
+			stats.TodoOverdue++
+		}
+	}
+	return &stats, nil
+}
+
+// emailTaken check whether another user already use the email, emulate the unique index
+func (r *memoryUserRepository) emailTaken(email *string, id int) bool {
+	if nil == email {
+		return false
+	}
+	for _, user := range r.store.users {
+		if user.ID != id && nil != user.Email && *user.Email == *email {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+)
+
+// ErrInvalidReassign status to reassign the todo doesn't exist or is the deleted status
+var ErrInvalidReassign = errors.New("Invalid reassign_to status")
+
+// ErrTrashedDependency todo can't be restored while its status or any assignee is in the trash
+var ErrTrashedDependency = errors.New("Todo status or assignee is in the trash")
+
+// StatusInUseError status can't be deleted while todo still use it
+type StatusInUseError struct {
+	Used int64 // number of todo using the status
+}
+
+func (e *StatusInUseError) Error() string {
+	return fmt.Sprintf("Status is used by %d todo", e.Used)
+}
+
+// TodoFilter filter of the todo list, nil field is not filtered
+type TodoFilter struct {
+	StatusID    *int
+	StatusText  *string
+	AssigneeID  *int
+	DueDateFrom *string // inclusive, format YYYY-MM-DD
+	DueDateTo   *string // inclusive, format YYYY-MM-DD
+}
+
+// UserFilter filter of the user list, nil field is not filtered
+type UserFilter struct {
+	Name       *string // case-insensitive contains
+	NamePrefix *string // case-insensitive prefix
+}
+
+// UserStats counts of the todo assigned to the user
+type UserStats struct {
+	TodoTotal    int64            `json:"todo_total"`
+	TodoDone     int64            `json:"todo_done"`      // todo in terminal status
+	TodoOverdue  int64            `json:"todo_overdue"`   // past the due date and not done
+	TodoByStatus map[string]int64 `json:"todo_by_status"` // by status text, empty text for todo without status
+}
+
+// TodoRepository todo data access, the returned todo has its status and assignees loaded
+type TodoRepository interface {
+	Create(ctx context.Context, todo *model.Todo) error // create with the assignees
+	Find(ctx context.Context, id int) (*model.Todo, error)
+	List(ctx context.Context, filter TodoFilter, page lib.Pagination) ([]model.Todo, int64, error)
+	ListCursor(ctx context.Context, filter TodoFilter, page lib.CursorPagination) ([]model.Todo, error)
+	// Update replace every field and the assignees at the record version, the empty fields are cleared,
+	// the assignees in the trash are kept until they are restored or purged
+	Update(ctx context.Context, todo *model.Todo) error
+	Delete(ctx context.Context, id int, version int) error  // delete at the version, any version when it is 0
+	AssigneeIDs(ctx context.Context, id int) ([]int, error) // every assignee id of the todo, the users in the trash too
+}
+
+// UserRepository user data access
+type UserRepository interface {
+	Create(ctx context.Context, user *model.User) error
+	Find(ctx context.Context, id int) (*model.User, error)
+	FindWithTrashed(ctx context.Context, id int) (*model.User, error) // the user even when it is in the trash
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindByIDs(ctx context.Context, ids []int) ([]model.User, error)
+	ListCursor(ctx context.Context, filter UserFilter, page lib.CursorPagination) ([]model.User, error)
+	Update(ctx context.Context, user *model.User) error    // replace every field at the record version, the empty fields are cleared
+	Delete(ctx context.Context, id int, version int) error // delete at the version, any version when it is 0
+	Stats(ctx context.Context, id int) (*UserStats, error) // stats of the todo assigned to the user
+}
+
+// StatusRepository status and status workflow data access
+type StatusRepository interface {
+	Create(ctx context.Context, status *model.Status) error
+	Find(ctx context.Context, id int) (*model.Status, error)
+	FindByText(ctx context.Context, text string) (*model.Status, error)
+	FindByIDs(ctx context.Context, ids []int) ([]model.Status, error)
+	ListCursor(ctx context.Context, page lib.CursorPagination) ([]model.Status, error)
+	Update(ctx context.Context, status *model.Status) error // replace every field at the record version, the empty fields are cleared
+	// Delete delete the status at the version, any version when it is 0, the todo using it
+	// are moved to reassignTo status when it is not 0, otherwise StatusInUseError is returned
+	Delete(ctx context.Context, id int, version int, reassignTo int) error
+	// Allowed next status allowed from the status ordered by position,
+	// terminal status doesn't allow any, status without transition allow every other status
+	Allowed(ctx context.Context, status *model.Status) ([]model.Status, error)
+	ReplaceTransitions(ctx context.Context, id int, toStatusIDs []int) error
+	AddTransitions(ctx context.Context, id int, toStatusIDs []int) error // the existing transitions are kept
+}
+
+// TrashRepository soft deleted data of the resource "todos", "users" or "status",
+// the data which isn't in the trash and the unknown resource are not found
+type TrashRepository interface {
+	// ListCursor list of the trashed data, *[]model.Todo with the status and assignees, *[]model.User or *[]model.Status
+	ListCursor(ctx context.Context, resource string, page lib.CursorPagination) (interface{}, error)
+	// Restore undo the soft delete and return the restored data, the todo can't be restored
+	// while its status or any assignee is in the trash, ErrTrashedDependency is returned
+	Restore(ctx context.Context, resource string, id int) (interface{}, error)
+	// Purge permanently delete the trashed data and its relations,
+	// the status still used by any todo return services.ErrStatusInUse
+	Purge(ctx context.Context, resource string, id int) error
+}
+
+// TokenRepository refresh token stored for revocation
+type TokenRepository interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	// Revoke revoke the refresh token by its token id, services.ErrInvalidToken when it is unknown or already revoked
+	Revoke(ctx context.Context, tokenID string) (*model.RefreshToken, error)
+}
+
+// The Update and the Delete at a version of every repository fail with services.ErrSerializationFailure
+// when the record was updated since the version was read, the version is incremented on update
+
+// Repositories data access of every resource
+type Repositories struct {
+	Todos  TodoRepository
+	Users  UserRepository
+	Status StatusRepository
+	Trash  TrashRepository
+	Tokens TokenRepository
+}
@@ -2,40 +2,94 @@ package routes
 
 import (
 	"github.com/razanlrahardjo/hacktiv8/app/controller"
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/middleware"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
 	"github.com/razanlrahardjo/hacktiv8/app/services"
+	"github.com/razanlrahardjo/hacktiv8/docs"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/spf13/viper"
 )
 
-// Handle all request to route to controller
+// Handle all request to route to controller, the database must be initialized
 func Handle(app *fiber.App) {
-	app.Use(cors.New())
-	services.InitDatabase()
+	app.Use(middleware.RequestID())
+	app.Use(middleware.AccessLog())
+	app.Use(middleware.Metrics())
+	app.Use(cors.New(cors.Config{ExposeHeaders: fiber.HeaderETag}))
 
+	// Probes of the container orchestrator and prometheus scrape, outside of the api endpoint
+	app.Get("/healthz", controller.Healthz)
+	app.Get("/readyz", controller.Readyz)
+	app.Get("/metrics", middleware.MetricsHandler())
+
+	// Api documentation, outside of the api endpoint
+	controller.ConfigureDocs(viper.GetString("ENDPOINT"))
+	app.Get("/docs", controller.DocsUI)
+	app.Get(controller.DocsSpecPath, controller.DocsSpec)
+	app.Use("/docs", controller.DocsAssets())
+
+	// Reject the request which doesn't conform to the openapi document before reaching the handler,
+	// the protected routes are authenticated first so the anonymous client can't probe the validation
+	spec, err := lib.ParseOpenAPI(docs.OpenAPI)
+	if nil != err {
+		panic(err)
+	}
+	validate := middleware.ValidateRequest(spec, viper.GetString("ENDPOINT"))
+
+	handler := controller.NewHandler(repository.NewRepositories(services.DB))
 	api := app.Group(viper.GetString("ENDPOINT"))
 
 	api.Get("/", controller.ApiIndexGet)
 
+	// Auth Routing
+	api.Post("/auth/login", validate, handler.Login)
+	api.Post("/auth/refresh", validate, handler.RefreshToken)
+	api.Post("/auth/logout", validate, handler.Logout)
+
+	// User sign up
+	api.Post("/users", validate, handler.PostUser)
+
+	// The owner of the account in the trash restore or purge it
+	authTrashed := middleware.AuthTrashed(handler.Users)
+	api.Post("/users/:id/restore", authTrashed, validate, handler.RestoreUser)
+	api.Delete("/trash/users/:id", authTrashed, validate, handler.DeleteTrashUser)
+
+	// Routes below require access token
+	api.Use(middleware.Auth(handler.Users), validate)
+
 	// User Routing
-	api.Post("/users", controller.PostUser)
-	api.Get("/users", controller.GetUser)
-	api.Put("/users/:id", controller.PutUser)
-	api.Delete("/users/:id", controller.DeleteUser)
+	api.Get("/users", handler.GetUser)
+	api.Get("/users/:id", handler.GetUserID)
+	api.Get("/users/:id/todos", handler.GetUserTodo)
+	api.Put("/users/:id", handler.PutUser)
+	api.Patch("/users/:id", handler.PatchUser)
+	api.Delete("/users/:id", handler.DeleteUser)
 
 	// Todo Routing
-	api.Post("/todos", controller.PostTodo)
-	api.Get("/todos", controller.GetTodo)
-	api.Get("/todos/:id", controller.GetTodoID)
-	api.Put("/todos/:id", controller.PutTodo)
-	api.Delete("/todos/:id", controller.DeleteTodo)
+	api.Post("/todos", handler.PostTodo)
+	api.Get("/todos", handler.GetTodo)
+	api.Get("/todos/:id", handler.GetTodoID)
+	api.Put("/todos/:id", handler.PutTodo)
+	api.Patch("/todos/:id", handler.PatchTodo)
+	api.Delete("/todos/:id", handler.DeleteTodo)
+	api.Post("/todos/:id/restore", handler.RestoreTodo)
 
 	// Status Routing
-	api.Post("/status", controller.PostStatus)
-	api.Get("/status", controller.GetStatus)
-	api.Get("/status/:id", controller.GetStatusID)
-	api.Put("/status/:id", controller.PutStatus)
-	api.Delete("/status/:id", controller.DeleteStatus)
+	api.Post("/status", handler.PostStatus)
+	api.Get("/status", handler.GetStatus)
+	api.Get("/status/:id", handler.GetStatusID)
+	api.Get("/status/:id/transitions", handler.GetStatusTransition)
+	api.Put("/status/:id/transitions", handler.PutStatusTransition)
+	api.Put("/status/:id", handler.PutStatus)
+	api.Patch("/status/:id", handler.PatchStatus)
+	api.Delete("/status/:id", handler.DeleteStatus)
+	api.Post("/status/:id/restore", handler.RestoreStatus)
+
+	// Trash Routing
+	api.Get("/trash/:resource", handler.GetTrash)
+	api.Delete("/trash/:resource/:id", handler.DeleteTrash)
 
 }
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+const (
+	// TokenTypeAccess access token type claim
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh refresh token type claim
+	TokenTypeRefresh = "refresh"
+)
+
+// ErrInvalidToken token is malformed, expired, revoked or signed with another key
+var ErrInvalidToken = errors.New("Invalid token")
+
+// ErrTokenSecret JWT_SECRET is not configured
+var ErrTokenSecret = errors.New("JWT_SECRET is not configured")
+
+// TokenClaims jwt claims of the access and refresh token
+type TokenClaims struct {
+	jwt.RegisteredClaims
+	Type string `json:"typ"`
+}
+
+// TokenPair issued access and refresh token
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // access token lifetime in seconds
+}
+
+// tokenSecret signing key of the token type, refresh token fallback to JWT_SECRET when JWT_REFRESH_SECRET is empty
+func tokenSecret(tokenType string) []byte {
+	if tokenType == TokenTypeRefresh && viper.GetString("JWT_REFRESH_SECRET") != "" {
+		return []byte(viper.GetString("JWT_REFRESH_SECRET"))
+	}
+	return []byte(viper.GetString("JWT_SECRET"))
+}
+
+// CheckTokenSecret check the token signing key is configured, no token can be issued or accepted without it
+func CheckTokenSecret() error {
+	if len(tokenSecret(TokenTypeAccess)) == 0 {
+		return ErrTokenSecret
+	}
+	return nil
+}
+
+// tokenTTL lifetime of the token type from JWT_ACCESS_TTL and JWT_REFRESH_TTL
+func tokenTTL(tokenType string) time.Duration {
+	key, ttl := "JWT_ACCESS_TTL", 15*time.Minute
+	if tokenType == TokenTypeRefresh {
+		key, ttl = "JWT_REFRESH_TTL", 30*24*time.Hour
+	}
+	if value, err := time.ParseDuration(viper.GetString(key)); nil == err && value > 0 {
+		ttl = value
+	}
+	return ttl
+}
+
+func signToken(userID int, tokenType string, tokenID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(tokenTTL(tokenType))
+	claims := TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Subject:   strconv.Itoa(userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Type: tokenType,
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(tokenSecret(tokenType))
+	return signed, expiresAt, err
+}
+
+// ParseToken verify the token signature, expiry and type, return the claims
+func ParseToken(token string, tokenType string) (*TokenClaims, error) {
+	claims := TokenClaims{}
+	parsed, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		secret := tokenSecret(tokenType)
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok || len(secret) == 0 {
+			return nil, ErrInvalidToken
+		}
+		return secret, nil
+	})
+	if nil != err || !parsed.Valid || claims.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+// IssueToken sign a new access token and a new refresh token,
+// the returned refresh token must be stored for revocation
+func IssueToken(user *model.User) (*TokenPair, *model.RefreshToken, error) {
+	if err := CheckTokenSecret(); nil != err {
+		return nil, nil, err
+	}
+
+	access, _, err := signToken(user.ID, TokenTypeAccess, uuid.New().String())
+	if nil != err {
+		return nil, nil, err
+	}
+
+	tokenID := uuid.New().String()
+	refresh, expiresAt, err := signToken(user.ID, TokenTypeRefresh, tokenID)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(tokenTTL(TokenTypeAccess).Seconds()),
+	}, &model.RefreshToken{UserID: user.ID, TokenID: tokenID, ExpiresAt: expiresAt}, nil
+}
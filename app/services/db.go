@@ -2,69 +2,107 @@ package services
 
 import (
 	"fmt"
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
 	"github.com/razanlrahardjo/hacktiv8/app/migrations"
 	"github.com/spf13/viper"
-	"gorm.io/gorm/logger"
+	"strings"
 	"time"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 // DB Main database connection
 var DB *gorm.DB
 
-// InitDatabase initialize database connection
+// InitDatabase initialize database connection, the schema is migrated by MigrateDatabase
 func InitDatabase() {
 	if nil == DB {
-		db := dbConnect()
-		if nil != db {
-			DB = db
-			dbMigrate()
-		}
+		DB = dbConnect()
+	}
+}
+
+// CloseDatabase close the main database connection
+func CloseDatabase() error {
+	if nil == DB {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if nil != err {
+		return err
 	}
+	DB = nil
+	return sqlDB.Close()
+}
+
+// OpenDatabase open new database connection without migrating the schema
+func OpenDatabase() *gorm.DB {
+	return dbConnect()
 }
 
 func dbConnect() *gorm.DB {
-	logLevel := logger.Info
 	config := gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+		Logger: newGormLogger(),
 		//NamingStrategy: schema.NamingStrategy{
 		//	TablePrefix:   viper.GetString("DB_TABLE_PREFIX"),
 		//	SingularTable: true,
 		//},
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=Asia/Jakarta",
-		viper.GetString("DB_HOST"),
-		viper.GetString("DB_PORT"),
-		viper.GetString("DB_USER"),
-		viper.GetString("DB_PASS"),
-		viper.GetString("DB_NAME"),
-	)
-	db, err := gorm.Open(postgres.Open(dsn), &config)
+	dialector, memory := dbDialector()
+	db, err := gorm.Open(dialector, &config)
 
 	if nil != err {
 		panic(err)
 	}
+	if err := db.Use(metricsPlugin{}); nil != err {
+		panic(err)
+	}
 
 	if nil != db {
 		sqlDB, _ := db.DB()
-		sqlDB.SetMaxIdleConns(1)
-		sqlDB.SetConnMaxLifetime(time.Second * 5)
+		if memory {
+			// every connection of in-memory sqlite is a new database, keep the only connection open
+			sqlDB.SetMaxOpenConns(1)
+		} else {
+			sqlDB.SetMaxIdleConns(1)
+			sqlDB.SetConnMaxLifetime(time.Second * 5)
+		}
 	}
 
 	return db
 }
 
-func dbMigrate() {
-	db := dbConnect()
-	if nil != db && len(migrations.ModelMigrations) > 0 {
-		err := db.AutoMigrate(migrations.ModelMigrations...)
-		if nil != err {
-			panic(err)
+// dbDialector database dialector by DB_DRIVER, postgres (default) or sqlite,
+// sqlite use DB_NAME as the database file, in-memory when DB_NAME is empty or ":memory:"
+func dbDialector() (dialector gorm.Dialector, memory bool) {
+	switch driver := strings.ToLower(viper.GetString("DB_DRIVER")); driver {
+	case "", "postgres", "postgresql":
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=Asia/Jakarta",
+			viper.GetString("DB_HOST"),
+			viper.GetString("DB_PORT"),
+			viper.GetString("DB_USER"),
+			viper.GetString("DB_PASS"),
+			viper.GetString("DB_NAME"),
+		)
+		return postgres.Open(dsn), false
+	case "sqlite", "sqlite3":
+		name := viper.GetString("DB_NAME")
+		if name == "" || name == ":memory:" {
+			return sqlite.Open("file::memory:?_foreign_keys=1"), true
 		}
-		sqlDB, _ := db.DB()
-		defer sqlDB.Close()
+		return sqlite.Open(fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000", name)), false
+	default:
+		panic(fmt.Sprintf("unsupported DB_DRIVER %s", driver))
+	}
+}
+
+// MigrateDatabase apply the pending migrations to the main database connection
+func MigrateDatabase() error {
+	applied, err := migrations.Up(DB)
+	for _, migration := range applied {
+		lib.Info("migration applied", lib.Fields{"version": migration.Version, "description": migration.Description})
 	}
+	return err
 }
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// Database error kinds, compare with errors.Is on the translated error
+var (
+	ErrNotFound             = errors.New("Not found")
+	ErrDuplicate            = errors.New("Duplicate data")
+	ErrForeignKeyViolation  = errors.New("Referenced data not found or still in use")
+	ErrNotNullViolation     = errors.New("Required data is missing")
+	ErrCheckViolation       = errors.New("Invalid data")
+	ErrSerializationFailure = errors.New("Concurrent update, please retry")
+	ErrConnection           = errors.New("Database unavailable")
+)
+
+// dbErrorKinds http status and problem code by database error kind
+var dbErrorKinds = map[error]struct {
+	status int
+	code   string
+}{
+	ErrNotFound:             {404, "not_found"},
+	ErrDuplicate:            {409, "duplicate"},
+	ErrForeignKeyViolation:  {409, "foreign_key_violation"},
+	ErrNotNullViolation:     {400, "not_null_violation"},
+	ErrCheckViolation:       {400, "check_violation"},
+	ErrSerializationFailure: {409, "serialization_failure"},
+	ErrConnection:           {503, "database_unavailable"},
+}
+
+// DBError database error classified by kind, the driver error is kept for the server log only
+type DBError struct {
+	Kind       error  // one of the database error kinds
+	Constraint string // violated constraint name when the driver report it
+	Err        error  // driver error
+}
+
+// Error message of the error kind, safe to be sent to client
+func (e *DBError) Error() string {
+	return e.Kind.Error()
+}
+
+// Unwrap error kind
+func (e *DBError) Unwrap() error {
+	return e.Kind
+}
+
+// Cause driver error
+func (e *DBError) Cause() error {
+	return e.Err
+}
+
+// HTTPStatus http status of the error kind
+func (e *DBError) HTTPStatus() int {
+	return dbErrorKinds[e.Kind].status
+}
+
+// Code problem code of the error kind
+func (e *DBError) Code() string {
+	return dbErrorKinds[e.Kind].code
+}
+
+// TranslateError classify the postgres and sqlite driver error into DBError,
+// unknown error is returned as is
+func TranslateError(err error) error {
+	if nil == err {
+		return nil
+	}
+	if e := (*DBError)(nil); errors.As(err, &e) {
+		return err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &DBError{Kind: ErrNotFound, Err: err}
+	}
+
+	if e := (*pgconn.PgError)(nil); errors.As(err, &e) {
+		if kind := pgErrorKind(e.Code); nil != kind {
+			return &DBError{Kind: kind, Constraint: e.ConstraintName, Err: err}
+		}
+		return err
+	}
+
+	if e := (sqlite3.Error{}); errors.As(err, &e) {
+		if kind := sqliteErrorKind(e); nil != kind {
+			return &DBError{Kind: kind, Err: err}
+		}
+		return err
+	}
+
+	var netError net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netError) || pgconn.Timeout(err) {
+		return &DBError{Kind: ErrConnection, Err: err}
+	}
+
+	return err
+}
+
+// pgErrorKind error kind by postgres SQLSTATE
+func pgErrorKind(code string) error {
+	switch {
+	case code == "23505":
+		return ErrDuplicate
+	case code == "23503":
+		return ErrForeignKeyViolation
+	case code == "23502":
+		return ErrNotNullViolation
+	case code == "23514":
+		return ErrCheckViolation
+	case code == "40001" || code == "40P01":
+		return ErrSerializationFailure
+	case strings.HasPrefix(code, "08") || code == "57P01" || code == "57P02" || code == "57P03":
+		return ErrConnection
+	}
+	return nil
+}
+
+// sqliteErrorKind error kind by sqlite result code
+func sqliteErrorKind(e sqlite3.Error) error {
+	switch e.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		return ErrDuplicate
+	case sqlite3.ErrConstraintForeignKey:
+		return ErrForeignKeyViolation
+	case sqlite3.ErrConstraintNotNull:
+		return ErrNotNullViolation
+	case sqlite3.ErrConstraintCheck:
+		return ErrCheckViolation
+	}
+	switch e.Code {
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return ErrSerializationFailure
+	case sqlite3.ErrCantOpen, sqlite3.ErrIoErr:
+		return ErrConnection
+	}
+	return nil
+}
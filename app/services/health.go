@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/migrations"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// HealthUp check passed
+	HealthUp = "up"
+	// HealthDown check failed
+	HealthDown = "down"
+)
+
+// readinessTimeout maximum duration of the readiness checks
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck result of a health check
+type HealthCheck struct {
+	Status    string  `json:"status"`           // up or down
+	LatencyMS float64 `json:"latency_ms"`       // duration of the check in millisecond
+	Detail    string  `json:"detail,omitempty"` // reason of the status
+}
+
+// Health overall status with the result of every check, down when any check is down
+type Health struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// Liveness health of the process, always up while the process can answer
+func Liveness() Health {
+	return Health{Status: HealthUp}
+}
+
+// Readiness health of the database, the migrations and the trash sweeper
+func Readiness(ctx context.Context) Health {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	health := Health{Status: HealthUp, Checks: map[string]HealthCheck{}}
+	for name, check := range map[string]func(ctx context.Context) (string, error){
+		"database":      checkDatabase,
+		"migrations":    checkMigrations,
+		"trash_sweeper": checkTrashSweeper,
+	} {
+		start := time.Now()
+		detail, err := check(ctx)
+		result := HealthCheck{
+			Status:    HealthUp,
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			Detail:    detail,
+		}
+		if nil != err {
+			result.Status = HealthDown
+			result.Detail = err.Error()
+			health.Status = HealthDown
+		}
+		health.Checks[name] = result
+	}
+	return health
+}
+
+// checkDatabase ping the main database connection
+func checkDatabase(ctx context.Context) (string, error) {
+	if nil == DB {
+		return "", errors.New("database is not connected")
+	}
+	sqlDB, err := DB.DB()
+	if nil != err {
+		return "", err
+	}
+	return "", sqlDB.PingContext(ctx)
+}
+
+// checkMigrations make sure every migration is applied
+func checkMigrations(ctx context.Context) (string, error) {
+	if nil == DB {
+		return "", errors.New("database is not connected")
+	}
+	status, err := migrations.Status(DB.WithContext(ctx))
+	if nil != err {
+		return "", err
+	}
+	pending := 0
+	for _, migration := range status {
+		if nil == migration.AppliedAt {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return "", fmt.Errorf("%d migrations pending", pending)
+	}
+	return fmt.Sprintf("%d migrations applied", len(status)), nil
+}
+
+// checkTrashSweeper make sure the trash sweeper is running unless it is disabled
+func checkTrashSweeper(ctx context.Context) (string, error) {
+	switch {
+	case nil == Sweeper && fiber.IsChild():
+		return "running in the prefork parent", nil
+	case nil == Sweeper:
+		return "", errors.New("trash sweeper is not started")
+	case Sweeper.Retention <= 0:
+		return "disabled", nil
+	case !Sweeper.Running():
+		return "", errors.New("trash sweeper is stopped")
+	}
+	return "", nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold query slower than this is logged as warning
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger gorm logger writing structured entries with the request id carried by the query context,
+// every SQL statement is logged at debug level, slow queries at warn and failed queries at error
+type gormLogger struct {
+	level *logger.LogLevel // set by LogMode, otherwise follow LOG_LEVEL
+}
+
+// newGormLogger gorm logger following LOG_LEVEL
+func newGormLogger() logger.Interface {
+	return &gormLogger{}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &gormLogger{level: &level}
+}
+
+// mode gorm log level of the logger
+func (l *gormLogger) mode() logger.LogLevel {
+	if nil != l.level {
+		return *l.level
+	}
+	switch lib.GetLogLevel() {
+	case lib.LevelDebug:
+		return logger.Info
+	case lib.LevelError:
+		return logger.Error
+	case lib.LevelSilent:
+		return logger.Silent
+	}
+	return logger.Warn
+}
+
+func (l *gormLogger) Info(ctx context.Context, message string, data ...interface{}) {
+	if l.mode() >= logger.Info {
+		lib.Info(fmt.Sprintf(message, data...), queryFields(ctx))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, message string, data ...interface{}) {
+	if l.mode() >= logger.Warn {
+		lib.Warn(fmt.Sprintf(message, data...), queryFields(ctx))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, message string, data ...interface{}) {
+	if l.mode() >= logger.Error {
+		lib.Error(fmt.Sprintf(message, data...), queryFields(ctx))
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	level := l.mode()
+	if level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := nil != err && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := elapsed > slowQueryThreshold
+	if !(failed && level >= logger.Error) && !(slow && level >= logger.Warn) && level < logger.Info {
+		return
+	}
+
+	sql, rows := fc()
+	fields := queryFields(ctx)
+	fields["sql"] = sql
+	fields["rows"] = rows
+	fields["latency_ms"] = float64(elapsed.Microseconds()) / 1000
+	switch {
+	case failed && level >= logger.Error:
+		fields["error"] = err
+		lib.Error("query failed", fields)
+	case slow && level >= logger.Warn:
+		lib.Warn("slow query", fields)
+	default:
+		lib.Debug("query", fields)
+	}
+}
+
+// queryFields log fields of the query context
+func queryFields(ctx context.Context) lib.Fields {
+	fields := lib.Fields{}
+	if id := lib.RequestIDFromContext(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	return fields
+}
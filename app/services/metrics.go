@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gorm_query_duration_seconds",
+		Help:    "Duration of the database queries by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	queryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gorm_query_errors_total",
+		Help: "Failed database queries by operation and table, record not found is not counted.",
+	}, []string{"operation", "table"})
+	todoByStatus = prometheus.NewDesc("todo_total", "Todos by status text.", []string{"status"}, nil)
+	todoOverdue  = prometheus.NewDesc("todo_overdue", "Todos past the due date which status isn't terminal.", nil, nil)
+)
+
+func init() {
+	prometheus.MustRegister(queryDuration, queryErrors, dbStatsCollector{}, todoCollector{})
+}
+
+// metricsStartKey gorm instance key of the query start time
+const metricsStartKey = "metrics:start"
+
+// metricsPlugin gorm plugin observing the query duration and errors
+type metricsPlugin struct{}
+
+func (metricsPlugin) Name() string {
+	return "metrics"
+}
+
+func (metricsPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("metrics:before_create", startQuery),
+		callback.Create().After("gorm:create").Register("metrics:after_create", observeQuery("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", startQuery),
+		callback.Query().After("gorm:query").Register("metrics:after_query", observeQuery("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", startQuery),
+		callback.Update().After("gorm:update").Register("metrics:after_update", observeQuery("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", startQuery),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", observeQuery("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", startQuery),
+		callback.Row().After("gorm:row").Register("metrics:after_row", observeQuery("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", startQuery),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", observeQuery("raw")),
+	} {
+		if nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+func observeQuery(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		queryDuration.WithLabelValues(operation, table).Observe(time.Since(value.(time.Time)).Seconds())
+		if nil != db.Error && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			queryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
+
+// dbStatsCollector connection pool stats of the main database connection,
+// the connection is read on collect as it is opened after the registration
+type dbStatsCollector struct{}
+
+func (dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	collectors.NewDBStatsCollector(nil, "main").Describe(ch)
+}
+
+func (dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	if nil == DB {
+		return
+	}
+	if sqlDB, err := DB.DB(); nil == err {
+		collectors.NewDBStatsCollector(sqlDB, "main").Collect(ch)
+	}
+}
+
+// todoCollector todo gauges queried on every scrape
+type todoCollector struct{}
+
+func (todoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- todoByStatus
+	ch <- todoOverdue
+}
+
+func (todoCollector) Collect(ch chan<- prometheus.Metric) {
+	if nil == DB {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), readinessTimeout)
+	defer cancel()
+	// the scrape would flood the query log
+	db := DB.Session(&gorm.Session{Context: ctx, Logger: logger.Discard})
+
+	rows := []struct {
+		StatusText *string
+		Total      int64
+	}{}
+	err := db.Model(&model.Todo{}).
+		Select("status.status_text, count(*) AS total").
+		Joins("LEFT JOIN status ON status.id = todo.status_id").
+		Group("status.status_text").
+		Scan(&rows).Error
+	if nil == err {
+		for _, row := range rows {
+			status := ""
+			if nil != row.StatusText {
+				status = *row.StatusText
+			}
+			ch <- prometheus.MustNewConstMetric(todoByStatus, prometheus.GaugeValue, float64(row.Total), status)
+		}
+	}
+
+	var overdue int64
+	err = db.Model(&model.Todo{}).
+		Joins("LEFT JOIN status ON status.id = todo.status_id").
+		Where("todo.due_date < ?", time.Now().Format("2006-01-02")).
+		Where("status.is_terminal IS NULL OR status.is_terminal = ?", false).
+		Count(&overdue).Error
+	if nil == err {
+		ch <- prometheus.MustNewConstMetric(todoOverdue, prometheus.GaugeValue, float64(overdue))
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"gorm.io/gorm"
+)
+
+// Paginate scope to apply order, offset and limit of the page
+func Paginate(p lib.Pagination) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, sort := range p.Sort {
+			db = db.Order(sort)
+		}
+		return db.Order("id asc").Offset(p.Offset()).Limit(p.Limit)
+	}
+}
+
+// CursorPaginate scope to walk the (updated_at, id) ordering from the cursor,
+// fetch one more row than the limit to detect whether more rows exist
+func CursorPaginate(p lib.CursorPagination) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if nil != p.Cursor && p.Cursor.Backward {
+			db = db.Where("updated_at < ? OR (updated_at = ? AND id < ?)", p.Cursor.UpdatedAt, p.Cursor.UpdatedAt, p.Cursor.ID).
+				Order("updated_at desc").Order("id desc")
+		} else {
+			if nil != p.Cursor {
+				db = db.Where("updated_at > ? OR (updated_at = ? AND id > ?)", p.Cursor.UpdatedAt, p.Cursor.UpdatedAt, p.Cursor.ID)
+			}
+			db = db.Order("updated_at asc").Order("id asc")
+		}
+		return db.Limit(p.Limit + 1)
+	}
+}
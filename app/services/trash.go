@@ -0,0 +1,194 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// TrashModels models purged by the sweeper, in purge order
+var TrashModels []interface{} = []interface{}{
+	&model.Todo{},
+	&model.User{},
+	&model.Status{},
+}
+
+// ErrStatusInUse status can't be purged while any todo still reference it
+var ErrStatusInUse = fmt.Errorf("Status is used by todo")
+
+// Purge permanently delete the soft deleted data and its relations
+func Purge(db *gorm.DB, value interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		switch data := value.(type) {
+		case *model.Todo:
+			if err := tx.Exec("DELETE FROM todo_assignee WHERE todo_id = ?", data.ID).Error; err != nil {
+				return err
+			}
+		case *model.User:
+			if err := tx.Exec("DELETE FROM todo_assignee WHERE user_id = ?", data.ID).Error; err != nil {
+				return err
+			}
+		case *model.Status:
+			var used int64
+			if err := tx.Unscoped().Model(&model.Todo{}).Where("status_id = ?", data.ID).Count(&used).Error; err != nil {
+				return err
+			}
+			if used > 0 {
+				return ErrStatusInUse
+			}
+			if err := tx.Unscoped().Where("from_status_id = ? OR to_status_id = ?", data.ID, data.ID).Delete(&model.StatusTransition{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Delete(value).Error
+	})
+}
+
+// PurgeExpired permanently delete the data soft deleted before the time
+func PurgeExpired(db *gorm.DB, before time.Time) (int64, error) {
+	var purged int64
+	for _, trashModel := range TrashModels {
+		ids := []int{}
+		if err := db.Unscoped().Model(trashModel).Where("deleted_at < ?", before).Pluck("id", &ids).Error; err != nil {
+			return purged, err
+		}
+
+		for _, id := range ids {
+			data := newModel(trashModel, id)
+			if err := Purge(db, data); err == ErrStatusInUse {
+				continue
+			} else if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// newModel new pointer of the same model type with the id
+func newModel(value interface{}, id int) interface{} {
+	base := model.Base{ID: id}
+	switch value.(type) {
+	case *model.Todo:
+		return &model.Todo{Base: base}
+	case *model.User:
+		return &model.User{Base: base}
+	case *model.Status:
+		return &model.Status{Base: base}
+	}
+	return nil
+}
+
+// TrashSweeper background worker purging the data trashed longer than the retention period
+type TrashSweeper struct {
+	Retention time.Duration // how long the data stay in the trash
+	Interval  time.Duration // how often the sweeper run
+
+	mutex   sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// Sweeper trash sweeper started with the application
+var Sweeper *TrashSweeper
+
+// NewTrashSweeper trash sweeper configured by TRASH_RETENTION and TRASH_SWEEP_INTERVAL,
+// default to 30 days retention swept every hour
+func NewTrashSweeper() *TrashSweeper {
+	sweeper := &TrashSweeper{
+		Retention: 30 * 24 * time.Hour,
+		Interval:  time.Hour,
+	}
+	if retention, err := time.ParseDuration(viper.GetString("TRASH_RETENTION")); nil == err {
+		sweeper.Retention = retention
+	}
+	if interval, err := time.ParseDuration(viper.GetString("TRASH_SWEEP_INTERVAL")); nil == err && interval > 0 {
+		sweeper.Interval = interval
+	}
+	return sweeper
+}
+
+// StartTrashSweeper start the trash sweeper unless the retention is disabled
+func StartTrashSweeper() {
+	if nil == Sweeper {
+		Sweeper = NewTrashSweeper()
+	}
+	if Sweeper.Retention > 0 {
+		Sweeper.Start()
+	}
+}
+
+// StopTrashSweeper stop the trash sweeper started by StartTrashSweeper
+func StopTrashSweeper() {
+	if nil != Sweeper {
+		Sweeper.Stop()
+	}
+}
+
+// Start run the sweeper in background
+func (s *TrashSweeper) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		for {
+			s.Sweep()
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Sweep purge the expired trash once
+func (s *TrashSweeper) Sweep() {
+	if nil == DB {
+		return
+	}
+	purged, err := PurgeExpired(DB, time.Now().Add(-s.Retention))
+	if nil != err {
+		lib.Error("trash sweep failed", lib.Fields{"error": err})
+	} else if purged > 0 {
+		lib.Info("trash swept", lib.Fields{"purged": purged})
+	}
+}
+
+// Stop the sweeper and wait until the running sweep finished
+func (s *TrashSweeper) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.running = false
+}
+
+// Running check whether the sweeper is running
+func (s *TrashSweeper) Running() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.running
+}
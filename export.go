@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// export write the users, status and todos as a seed fixture, the user passwords are not exported
+func export(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "output file, default to stdout")
+	format := flags.String("format", "", "json or yaml, default to the output file extension or json")
+	flags.Parse(args)
+	if *format == "" {
+		*format = fixtureFormat(*output)
+	}
+
+	db := services.OpenDatabase()
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	// the query log would be mixed with the fixture written to stdout
+	data, err := exportFixture(db.Session(&gorm.Session{Logger: logger.Discard}))
+	if nil != err {
+		log.Fatal(err)
+	}
+	bte, err := encodeFixture(data, *format)
+	if nil != err {
+		log.Fatal(err)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(bte)
+		return
+	}
+	if err := ioutil.WriteFile(*output, bte, 0644); nil != err {
+		log.Fatal(err)
+	}
+}
+
+// exportFixture fixture of the data which isn't deleted
+func exportFixture(db *gorm.DB) (fixture, error) {
+	data := fixture{
+		Users:  []fixtureUser{},
+		Status: []fixtureStatus{},
+		Todos:  []fixtureTodo{},
+	}
+
+	users := []model.User{}
+	if err := db.Order("id asc").Find(&users).Error; nil != err {
+		return data, err
+	}
+	for _, user := range users {
+		data.Users = append(data.Users, fixtureUser{Name: user.Name, Email: user.Email})
+	}
+
+	status := []model.Status{}
+	if err := db.Order("position asc").Order("id asc").Find(&status).Error; nil != err {
+		return data, err
+	}
+	transitions := []model.StatusTransition{}
+	if err := db.Preload("ToStatus").Order("id asc").Find(&transitions).Error; nil != err {
+		return data, err
+	}
+	for _, item := range status {
+		next := []string{}
+		for _, transition := range transitions {
+			if transition.FromStatusID == item.ID && nil != transition.ToStatus && nil != transition.ToStatus.StatusText {
+				next = append(next, *transition.ToStatus.StatusText)
+			}
+		}
+		data.Status = append(data.Status, fixtureStatus{
+			StatusText:  item.StatusText,
+			IsTerminal:  item.IsTerminal,
+			Position:    item.Position,
+			Color:       item.Color,
+			Transitions: next,
+		})
+	}
+
+	todos := []model.Todo{}
+	if err := db.Scopes(repository.PreloadTodo).Order("id asc").Find(&todos).Error; nil != err {
+		return data, err
+	}
+	for _, todo := range todos {
+		item := fixtureTodo{Title: todo.Title, Description: todo.Description, DueDate: todo.DueDate, Assignees: []string{}}
+		// sqlite return the date column as timestamp
+		if nil != item.DueDate && len(*item.DueDate) > len("2006-01-02") {
+			dueDate := (*item.DueDate)[:len("2006-01-02")]
+			item.DueDate = &dueDate
+		}
+		if nil != todo.Status {
+			item.Status = todo.Status.StatusText
+		}
+		for _, user := range todo.Assignees {
+			if nil != user.Email {
+				item.Assignees = append(item.Assignees, *user.Email)
+			}
+		}
+		data.Todos = append(data.Todos, item)
+	}
+
+	return data, nil
+}
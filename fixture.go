@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fixture users, status and todos loaded by seed and written by export,
+// the data refer each other by email and status text instead of id
+type fixture struct {
+	Users  []fixtureUser   `json:"users" yaml:"users"`
+	Status []fixtureStatus `json:"status" yaml:"status"`
+	Todos  []fixtureTodo   `json:"todos" yaml:"todos"`
+}
+
+type fixtureUser struct {
+	Name     *string `json:"name" yaml:"name"`
+	Email    *string `json:"email" yaml:"email"`
+	Password *string `json:"password,omitempty" yaml:"password,omitempty"` // never exported
+}
+
+type fixtureStatus struct {
+	StatusText  *string  `json:"status_text" yaml:"status_text"`
+	IsTerminal  *bool    `json:"is_terminal,omitempty" yaml:"is_terminal,omitempty"`
+	Position    *int     `json:"position,omitempty" yaml:"position,omitempty"`
+	Color       *string  `json:"color,omitempty" yaml:"color,omitempty"`
+	Transitions []string `json:"transitions,omitempty" yaml:"transitions,omitempty"` // status text of the next status
+}
+
+type fixtureTodo struct {
+	Title       *string  `json:"title" yaml:"title"`
+	Description *string  `json:"description" yaml:"description"`
+	DueDate     *string  `json:"due_date" yaml:"due_date"`
+	Status      *string  `json:"status" yaml:"status"`       // status text
+	Assignees   []string `json:"assignees" yaml:"assignees"` // assignee email
+}
+
+// fixtureFormat json or yaml format by the file extension, json when the extension is unknown
+func fixtureFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return "json"
+}
+
+// readFixture read the YAML or JSON fixture file
+func readFixture(path string) (fixture, error) {
+	data := fixture{}
+	bte, err := ioutil.ReadFile(path)
+	if nil != err {
+		return data, err
+	}
+
+	if fixtureFormat(path) == "yaml" {
+		err = yaml.Unmarshal(bte, &data)
+	} else {
+		err = json.Unmarshal(bte, &data)
+	}
+	if nil != err {
+		return data, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+// encodeFixture encode the fixture to the json or yaml format
+func encodeFixture(data fixture, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		bte, err := json.MarshalIndent(data, "", "  ")
+		return append(bte, '\n'), err
+	case "yaml":
+		return yaml.Marshal(data)
+	}
+	return nil, fmt.Errorf("unknown format %s, use json or yaml", format)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/migrations"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openFixtureDatabase migrated in-memory sqlite database
+func openFixtureDatabase(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=1"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	utils.AssertEqual(t, nil, err, "Opening database")
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	_, err = migrations.Up(db)
+	utils.AssertEqual(t, nil, err, "Applying migrations")
+	return db
+}
+
+func TestFixtureRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	example, err := readFixture("seed.example.yaml")
+	utils.AssertEqual(t, nil, err, "Reading example fixture")
+
+	db := openFixtureDatabase(t)
+	repositories := repository.NewRepositories(db)
+	created, err := seedFixture(ctx, repositories, example)
+	utils.AssertEqual(t, nil, err, "Seeding example fixture")
+	utils.AssertEqual(t, len(example.Todos), len(created.Todos), "Every todo created")
+
+	todo, _ := repositories.Status.FindByText(ctx, "Todo")
+	done, _ := repositories.Status.FindByText(ctx, "Done")
+	utils.AssertEqual(t, nil, repositories.Status.AddTransitions(ctx, todo.ID, []int{done.ID}), "Adding transition")
+	_, err = seedFixture(ctx, repositories, example)
+	utils.AssertEqual(t, nil, err, "Seeding example fixture again")
+	allowed, _ := repositories.Status.Allowed(ctx, todo)
+	utils.AssertEqual(t, 2, len(allowed), "Existing transition kept")
+
+	// todo created before the description and assignees were required
+	title, dueDate := "Legacy", "2021-09-01"
+	utils.AssertEqual(t, nil, db.Create(&model.Todo{Title: &title, DueDate: &dueDate, StatusID: &done.ID}).Error, "Creating legacy todo")
+
+	exported, err := exportFixture(db)
+	utils.AssertEqual(t, nil, err, "Exporting fixture")
+	utils.AssertEqual(t, len(example.Users), len(exported.Users), "Exported users")
+	utils.AssertEqual(t, len(example.Status), len(exported.Status), "Exported status")
+	utils.AssertEqual(t, len(example.Todos)+1, len(exported.Todos), "Exported todos")
+	utils.AssertEqual(t, 0, len(exported.Todos[len(example.Todos)].Assignees), "Exported unassigned todo")
+
+	dir, err := ioutil.TempDir("", "fixture")
+	utils.AssertEqual(t, nil, err, "Creating temp dir")
+	defer os.RemoveAll(dir)
+
+	for _, format := range []string{"yaml", "json"} {
+		bte, err := encodeFixture(exported, format)
+		utils.AssertEqual(t, nil, err, format+" encoding fixture")
+		path := filepath.Join(dir, "fixture."+format)
+		utils.AssertEqual(t, nil, ioutil.WriteFile(path, bte, 0644), format+" writing fixture")
+		data, err := readFixture(path)
+		utils.AssertEqual(t, nil, err, format+" reading fixture")
+		decoded, _ := encodeFixture(data, format)
+		utils.AssertEqual(t, string(bte), string(decoded), format+" fixture decoded as exported")
+
+		created, err = seedFixture(ctx, repository.NewRepositories(db), data)
+		utils.AssertEqual(t, nil, err, format+" seeding exported fixture")
+		utils.AssertEqual(t, 0, len(created.Users)+len(created.Status)+len(created.Todos), format+" existing data kept")
+
+		// the passwords aren't exported, creating the users in the empty database require one
+		password := "password123"
+		for i := range data.Users {
+			data.Users[i].Password = &password
+		}
+		fresh := openFixtureDatabase(t)
+		_, err = seedFixture(ctx, repository.NewRepositories(fresh), data)
+		utils.AssertEqual(t, nil, err, format+" seeding empty database")
+		reexported, err := exportFixture(fresh)
+		utils.AssertEqual(t, nil, err, format+" exporting seeded database")
+		reencoded, _ := encodeFixture(reexported, format)
+		utils.AssertEqual(t, string(bte), string(reencoded), format+" fixture round trip")
+	}
+}
@@ -2,30 +2,50 @@ package main
 
 import (
 	"fmt"
-	"github.com/gofiber/fiber/v2"
-	"github.com/razanlrahardjo/hacktiv8/app/routes"
 	"github.com/spf13/viper"
-	"log"
 	"os"
 	"strings"
 )
 
-// @title Master API
-// @version 1.0.0
-// @description API Documentation
-// @termsOfService http://localhost:9000
-// @contact.name Developer
-// @contact.email razanlrahardjo@gmail.com
-// @host http://localhost:9000
-// @schemes http
-// @BasePath /api/v1/master
+// main run the command of the first argument, serve the api by default
 func main() {
-	app := fiber.New(fiber.Config{
-		Prefork: viper.GetString("PREFORK") == "true",
-	})
+	command, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
+
+	switch command {
+	case "serve":
+		serve(args)
+	case "migrate":
+		migrate(args)
+	case "seed":
+		seed(args)
+	case "export":
+		export(args)
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %s\n", command)
+		usage()
+		os.Exit(2)
+	}
+}
+
+// usage print the available commands
+func usage() {
+	fmt.Fprint(os.Stderr, `Usage: hacktiv8 <command> [flags]
+
+Commands:
+  serve                      start the api server (default)
+  migrate up                 apply the pending migrations
+  migrate down [steps]       roll back the last applied migrations, default 1 step
+  migrate status             list the migrations with the time they were applied
+  seed [-file fixture.yaml]  load the fixture users, status and todos from YAML or JSON file
+  export [-format json]      write the users, status and todos as a seed fixture
 
-	routes.Handle(app)
-	log.Fatal(app.Listen(":" + viper.GetString("PORT")))
+Run "hacktiv8 <command> -h" for the command flags.
+`)
 }
 
 func init() {
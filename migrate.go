@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/razanlrahardjo/hacktiv8/app/migrations"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+)
+
+// migrate run the migration command: up, down [steps] or status
+func migrate(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: hacktiv8 migrate [up | down [steps] | status]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	args = flags.Args()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	db := services.OpenDatabase()
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	switch command {
+	case "up":
+		applied, err := migrations.Up(db)
+		for _, migration := range applied {
+			fmt.Printf("applied %d %s\n", migration.Version, migration.Description)
+		}
+		if nil != err {
+			log.Fatal(err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			value, err := strconv.Atoi(args[1])
+			if nil != err || value < 1 {
+				log.Fatalf("invalid steps %s", args[1])
+			}
+			steps = value
+		}
+		rolledBack, err := migrations.Down(db, steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("rolled back %d %s\n", migration.Version, migration.Description)
+		}
+		if nil != err {
+			log.Fatal(err)
+		}
+	case "status":
+		status, err := migrations.Status(db)
+		if nil != err {
+			log.Fatal(err)
+		}
+		for _, migration := range status {
+			appliedAt := "pending"
+			if nil != migration.AppliedAt {
+				appliedAt = migration.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d  %-19s  %s\n", migration.Version, appliedAt, migration.Description)
+		}
+	default:
+		log.Fatalf("unknown migrate command %s, use up, down [steps] or status", command)
+	}
+}
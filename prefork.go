@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+)
+
+// preforkChildGrace time given to the prefork children over the shutdown timeout to close their resources
+const preforkChildGrace = 5 * time.Second
+
+// superviseChildren start a prefork child serving the api per cpu and forward the shutdown signal to them,
+// it replace the fiber prefork parent which kill every child as soon as one of them exit,
+// even while the others are still draining their requests
+func superviseChildren(signals <-chan os.Signal, timeout time.Duration) error {
+	children := []*exec.Cmd{}
+	exited := make(chan error, runtime.GOMAXPROCS(0))
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		cmd := exec.Command(os.Args[0], os.Args[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		// the same flag as fiber prefork, the child listen with SO_REUSEPORT
+		cmd.Env = append(os.Environ(), "FIBER_PREFORK_CHILD=1")
+		if err := cmd.Start(); nil != err {
+			stopChildren(children, syscall.SIGKILL)
+			return fmt.Errorf("prefork: %w", err)
+		}
+		children = append(children, cmd)
+		go func() {
+			exited <- cmd.Wait()
+		}()
+	}
+	lib.Info("prefork started", lib.Fields{"children": len(children)})
+
+	running := len(children)
+	var err error
+	select {
+	case childErr := <-exited:
+		running--
+		err = fmt.Errorf("prefork child exited: %v", childErr)
+	case sig := <-signals:
+		lib.Info("draining the prefork children", lib.Fields{"signal": sig.String()})
+	}
+
+	stopChildren(children, syscall.SIGTERM)
+	deadline := time.After(timeout + preforkChildGrace)
+	for running > 0 {
+		select {
+		case <-exited:
+			running--
+		case <-deadline:
+			stopChildren(children, syscall.SIGKILL)
+			return fmt.Errorf("prefork: %d children still running after %s, killed", running, timeout+preforkChildGrace)
+		}
+	}
+	return err
+}
+
+// stopChildren send the signal to the children, the exited children are ignored
+func stopChildren(children []*exec.Cmd, sig os.Signal) {
+	for _, cmd := range children {
+		cmd.Process.Signal(sig)
+	}
+}
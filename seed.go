@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"gorm.io/gorm"
+)
+
+// seed load the fixture file into the database, the data which already exist are kept
+func seed(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	file := flags.String("file", "", "YAML or JSON fixture file, may also be given as argument")
+	flags.Parse(args)
+	if *file == "" && flags.NArg() > 0 {
+		*file = flags.Arg(0)
+	}
+	if *file == "" {
+		flags.Usage()
+		log.Fatal("missing fixture file")
+	}
+
+	data, err := readFixture(*file)
+	if nil != err {
+		log.Fatal(err)
+	}
+
+	db := services.OpenDatabase()
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+
+	created := fixture{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		created, err = seedFixture(context.Background(), repository.NewRepositories(tx), data)
+		return err
+	})
+	if nil != err {
+		log.Fatal(err)
+	}
+	fmt.Printf("seeded %d users, %d status and %d todos from %s\n", len(created.Users), len(created.Status), len(created.Todos), *file)
+}
+
+// seedFixture create the fixture data which doesn't exist yet, return the created data,
+// user exist by email, status by status text and todo by title and due date
+func seedFixture(ctx context.Context, repositories repository.Repositories, data fixture) (fixture, error) {
+	created := fixture{}
+	for i, item := range data.Users {
+		if nil != item.Email {
+			if _, err := repositories.Users.FindByEmail(ctx, *item.Email); nil == err {
+				continue
+			} else if !errors.Is(err, services.ErrNotFound) {
+				return created, err
+			}
+		}
+
+		user := model.User{Name: item.Name, Email: item.Email, Password: item.Password}
+		if validation := user.Validation("create"); len(validation) != 0 {
+			return created, fixtureError("users", i, validation)
+		}
+		if err := repositories.Users.Create(ctx, &user); nil != err {
+			return created, fmt.Errorf("users %d: %w", i, err)
+		}
+		created.Users = append(created.Users, item)
+	}
+
+	for i, item := range data.Status {
+		if nil != item.StatusText {
+			if _, err := repositories.Status.FindByText(ctx, *item.StatusText); nil == err {
+				continue
+			} else if !errors.Is(err, services.ErrNotFound) {
+				return created, err
+			}
+		}
+
+		status := model.Status{StatusText: item.StatusText, IsTerminal: item.IsTerminal, Position: item.Position, Color: item.Color}
+		if validation := status.Validation("create"); len(validation) != 0 {
+			return created, fixtureError("status", i, validation)
+		}
+		if nil == status.IsTerminal {
+			terminal := false
+			status.IsTerminal = &terminal
+		}
+		if err := repositories.Status.Create(ctx, &status); nil != err {
+			return created, fmt.Errorf("status %d: %w", i, err)
+		}
+		created.Status = append(created.Status, item)
+	}
+
+	// the transitions refer to the status created above, the existing transitions are kept
+	for i, item := range data.Status {
+		if len(item.Transitions) == 0 || nil == item.StatusText {
+			continue
+		}
+		status, err := repositories.Status.FindByText(ctx, *item.StatusText)
+		if nil != err {
+			return created, fmt.Errorf("status %d: %w", i, err)
+		}
+		next := []int{}
+		for _, text := range item.Transitions {
+			to, err := repositories.Status.FindByText(ctx, text)
+			if nil != err {
+				return created, fmt.Errorf("status %d transition %s: %w", i, text, err)
+			}
+			next = append(next, to.ID)
+		}
+		if err := repositories.Status.AddTransitions(ctx, status.ID, next); nil != err {
+			return created, fmt.Errorf("status %d: %w", i, err)
+		}
+	}
+
+	for i, item := range data.Todos {
+		if exist, err := todoExist(ctx, repositories.Todos, item); nil != err {
+			return created, err
+		} else if exist {
+			continue
+		}
+
+		todo := model.Todo{Title: item.Title, Description: item.Description, DueDate: item.DueDate, AssigneeIDs: []int{}}
+		if nil != item.Status {
+			status, err := repositories.Status.FindByText(ctx, *item.Status)
+			if nil != err {
+				return created, fmt.Errorf("todos %d status %s: %w", i, *item.Status, err)
+			}
+			todo.StatusID = &status.ID
+		}
+		for _, email := range item.Assignees {
+			user, err := repositories.Users.FindByEmail(ctx, email)
+			if nil != err {
+				return created, fmt.Errorf("todos %d assignee %s: %w", i, email, err)
+			}
+			todo.AssigneeIDs = append(todo.AssigneeIDs, user.ID)
+			todo.Assignees = append(todo.Assignees, *user)
+		}
+
+		if validation := legacyTodoValidation(todo.Validation("create")); len(validation) != 0 {
+			return created, fixtureError("todos", i, validation)
+		}
+		if err := repositories.Todos.Create(ctx, &todo); nil != err {
+			return created, fmt.Errorf("todos %d: %w", i, err)
+		}
+		created.Todos = append(created.Todos, item)
+	}
+
+	return created, nil
+}
+
+// todoExist check whether todo with the same title and due date exist
+func todoExist(ctx context.Context, todos repository.TodoRepository, item fixtureTodo) (bool, error) {
+	if nil == item.Title || nil == item.DueDate {
+		return false, nil
+	}
+	filter := repository.TodoFilter{DueDateFrom: item.DueDate, DueDateTo: item.DueDate}
+	page := lib.Pagination{Page: 1, Limit: lib.MaxPageLimit}
+	for {
+		found, total, err := todos.List(ctx, filter, page)
+		if nil != err {
+			return false, err
+		}
+		for _, todo := range found {
+			if nil != todo.Title && *todo.Title == *item.Title {
+				return true, nil
+			}
+		}
+		if int64(page.Offset()+len(found)) >= total || len(found) == 0 {
+			return false, nil
+		}
+		page.Page++
+	}
+}
+
+// legacyTodoValidation field errors of the fixture todo without the required description and assignees,
+// the todo created before they were required is exported without them
+func legacyTodoValidation(validation []lib.FieldError) []lib.FieldError {
+	errors := []lib.FieldError{}
+	for _, field := range validation {
+		if field.Code == lib.CodeRequired && (field.Field == "description" || field.Field == "assignee_ids") {
+			continue
+		}
+		errors = append(errors, field)
+	}
+	return errors
+}
+
+// fixtureError invalid fixture item with its field errors
+func fixtureError(section string, index int, validation []lib.FieldError) error {
+	messages := []string{}
+	for _, field := range validation {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return fmt.Errorf("%s %d: %s", section, index, strings.Join(messages, ", "))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/routes"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+)
+
+// serve start the api server until SIGINT or SIGTERM is received,
+// then drain the active requests, stop the background workers and close the database
+func serve(args []string) {
+	timeout := 30 * time.Second
+	if value, err := time.ParseDuration(viper.GetString("SHUTDOWN_TIMEOUT")); nil == err && value > 0 {
+		timeout = value
+	}
+
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	port := flags.String("port", viper.GetString("PORT"), "listen port, default to PORT")
+	migrate := flags.Bool("migrate", true, "apply the pending migrations before serving")
+	flags.DurationVar(&timeout, "shutdown-timeout", timeout, "time to drain the active requests on shutdown, default to SHUTDOWN_TIMEOUT or 30s")
+	flags.Parse(args)
+	// the api docs advertise the port actually listened
+	viper.Set("PORT", *port)
+	prefork := viper.GetString("PREFORK") == "true"
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	if err := lib.CheckCursorSecret(); nil != err {
+		log.Fatal(err)
+	}
+	if err := services.CheckTokenSecret(); nil != err {
+		log.Fatal(err)
+	}
+
+	services.InitDatabase()
+	// prefork children share the database migrated and swept by the parent
+	if !fiber.IsChild() {
+		if *migrate {
+			if err := services.MigrateDatabase(); nil != err {
+				log.Fatal(err)
+			}
+		}
+		services.StartTrashSweeper()
+	}
+
+	var err error
+	if prefork && !fiber.IsChild() {
+		err = superviseChildren(signals, timeout)
+	} else {
+		err = listen(*port, prefork, signals, timeout)
+	}
+
+	services.StopTrashSweeper()
+	if closeErr := services.CloseDatabase(); nil != closeErr {
+		lib.Error("close database failed", lib.Fields{"error": closeErr})
+	}
+	if nil != err {
+		log.Fatal(err)
+	}
+}
+
+// listen serve the api until the signal is received, then stop accepting connection
+// and wait for the active requests up to the timeout
+func listen(port string, prefork bool, signals <-chan os.Signal, timeout time.Duration) error {
+	app := fiber.New(fiber.Config{
+		Prefork: prefork,
+	})
+	routes.Handle(app)
+
+	failed := make(chan error, 1)
+	go func() {
+		failed <- app.Listen(":" + port)
+	}()
+
+	select {
+	case err := <-failed:
+		return err
+	case sig := <-signals:
+		lib.Info("draining the active requests", lib.Fields{"signal": sig.String()})
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Shutdown()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("shutdown timeout %s exceeded, the active requests are closed", timeout)
+	}
+}
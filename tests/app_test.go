@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/routes"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+var (
+	testApp     *fiber.App
+	testAppOnce sync.Once
+)
+
+// newTestApp api served from in-memory sqlite database, shared by the controller tests
+func newTestApp() *fiber.App {
+	testAppOnce.Do(func() {
+		viper.Set("DB_DRIVER", "sqlite")
+		viper.Set("DB_NAME", ":memory:")
+		viper.Set("JWT_SECRET", "test-secret")
+		viper.Set("CURSOR_SECRET", "test-cursor-secret")
+		services.InitDatabase()
+		if err := services.MigrateDatabase(); err != nil {
+			panic(err)
+		}
+		testApp = fiber.New()
+		routes.Handle(testApp)
+	})
+	return testApp
+}
+
+// sendRequest send json request to the test app, return the status code and the decoded body
+func sendRequest(t *testing.T, method string, url string, body string, token string) (int, map[string]interface{}) {
+	return sendAppRequest(t, newTestApp(), method, url, body, token)
+}
+
+// sendAppRequest send json request to the app, return the status code and the decoded body
+func sendAppRequest(t *testing.T, app *fiber.App, method string, url string, body string, token string) (int, map[string]interface{}) {
+	return sendContentRequest(t, app, method, "application/json", url, body, token)
+}
+
+// sendPatchRequest send PATCH request of the patch media type to the app, return the status code and the decoded body
+func sendPatchRequest(t *testing.T, app *fiber.App, contentType string, url string, body string, token string) (int, map[string]interface{}) {
+	return sendContentRequest(t, app, "PATCH", contentType, url, body, token)
+}
+
+// sendContentRequest send request with the content type to the app, return the status code and the decoded body
+func sendContentRequest(t *testing.T, app *fiber.App, method string, contentType string, url string, body string, token string) (int, map[string]interface{}) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request := httptest.NewRequest(method, url, reader)
+	request.Header.Add("Content-Type", contentType)
+	if token != "" {
+		request.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	response, err := app.Test(request, -1)
+	utils.AssertEqual(t, nil, err, "Sending request")
+	defer response.Body.Close()
+	bte, err := ioutil.ReadAll(response.Body)
+	utils.AssertEqual(t, nil, err, "Reading response data")
+
+	result := map[string]interface{}{}
+	json.Unmarshal(bte, &result)
+	return response.StatusCode, result
+}
+
+// sendConditionalRequest send request with the conditional header to the api, return the status code and the ETag
+func sendConditionalRequest(t *testing.T, method string, contentType string, url string, body string, header string, etag string, token string) (int, string) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request := httptest.NewRequest(method, url, reader)
+	request.Header.Add("Content-Type", contentType)
+	request.Header.Add("Authorization", "Bearer "+token)
+	if etag != "" {
+		request.Header.Add(header, etag)
+	}
+
+	response, err := newTestApp().Test(request, -1)
+	utils.AssertEqual(t, nil, err, "Sending request")
+	defer response.Body.Close()
+	return response.StatusCode, response.Header.Get("ETag")
+}
+
+// signUp create new user and login, return the user id and the access token
+func signUp(t *testing.T) (float64, string) {
+	email := uuid.New().String() + "@example.com"
+	status, user := sendRequest(t, "POST", "/users", `{"name":"Tester","email":"`+email+`","password":"password123"}`, "")
+	utils.AssertEqual(t, 200, status, "Sign up")
+
+	status, token := sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`","password":"password123"}`, "")
+	utils.AssertEqual(t, 200, status, "Login")
+
+	return user["id"].(float64), token["access_token"].(string)
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+func TestAuthToken(t *testing.T) {
+	email := uuid.New().String() + "@example.com"
+	sendRequest(t, "POST", "/users", `{"name":"Auth","email":"`+email+`","password":"password123"}`, "")
+
+	status, _ := sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`","password":"password000"}`, "")
+	utils.AssertEqual(t, 401, status, "Wrong password")
+	status, _ = sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`"}`, "")
+	utils.AssertEqual(t, 400, status, "Missing password")
+	status, login := sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`","password":"password123"}`, "")
+	utils.AssertEqual(t, 200, status, "Login")
+	utils.AssertEqual(t, "Bearer", login["token_type"], "Bearer token")
+	access, refresh := login["access_token"].(string), login["refresh_token"].(string)
+
+	status, _ = sendRequest(t, "GET", "/users", "", access)
+	utils.AssertEqual(t, 200, status, "Access token accepted")
+	status, _ = sendRequest(t, "GET", "/users", "", refresh)
+	utils.AssertEqual(t, 401, status, "Refresh token is not an access token")
+	status, _ = sendRequest(t, "POST", "/auth/refresh", `{"refresh_token":"`+access+`"}`, "")
+	utils.AssertEqual(t, 401, status, "Access token is not a refresh token")
+
+	status, rotated := sendRequest(t, "POST", "/auth/refresh", `{"refresh_token":"`+refresh+`"}`, "")
+	utils.AssertEqual(t, 200, status, "Refreshing token")
+	utils.AssertEqual(t, false, refresh == rotated["refresh_token"], "Refresh token rotated")
+	status, _ = sendRequest(t, "POST", "/auth/refresh", `{"refresh_token":"`+refresh+`"}`, "")
+	utils.AssertEqual(t, 401, status, "Rotated refresh token revoked")
+	status, _ = sendRequest(t, "GET", "/users", "", rotated["access_token"].(string))
+	utils.AssertEqual(t, 200, status, "Rotated access token accepted")
+
+	status, _ = sendRequest(t, "POST", "/auth/logout", `{"refresh_token":"`+rotated["refresh_token"].(string)+`"}`, "")
+	utils.AssertEqual(t, 200, status, "Logout")
+	status, _ = sendRequest(t, "POST", "/auth/refresh", `{"refresh_token":"`+rotated["refresh_token"].(string)+`"}`, "")
+	utils.AssertEqual(t, 401, status, "Logged out refresh token revoked")
+	status, _ = sendRequest(t, "POST", "/auth/logout", `{"refresh_token":"`+rotated["refresh_token"].(string)+`"}`, "")
+	utils.AssertEqual(t, 401, status, "Logout twice")
+}
+
+func TestTokenSecret(t *testing.T) {
+	newTestApp()
+	claims := services.TokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Type: services.TokenTypeAccess,
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte{})
+	utils.AssertEqual(t, nil, err, "Signing with empty key")
+
+	_, err = services.ParseToken(unsigned, services.TokenTypeAccess)
+	utils.AssertEqual(t, services.ErrInvalidToken, err, "Empty key token with secret configured")
+	status, _ := sendRequest(t, "GET", "/users", "", unsigned)
+	utils.AssertEqual(t, 401, status, "Empty key token rejected")
+
+	viper.Set("JWT_SECRET", "")
+	defer viper.Set("JWT_SECRET", "test-secret")
+	_, err = services.ParseToken(unsigned, services.TokenTypeAccess)
+	utils.AssertEqual(t, services.ErrInvalidToken, err, "Empty key token without secret")
+	utils.AssertEqual(t, services.ErrTokenSecret, services.CheckTokenSecret(), "Secret required")
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/spf13/viper"
+)
+
+func TestCursor(t *testing.T) {
+	newTestApp()
+	cursor := lib.Cursor{UpdatedAt: time.Date(2021, 10, 10, 0, 0, 0, 0, time.UTC), ID: 7, Backward: true}
+	value := lib.EncodeCursor(cursor)
+
+	decoded, err := lib.DecodeCursor(value)
+	utils.AssertEqual(t, nil, err, "Decoding cursor")
+	utils.AssertEqual(t, cursor.ID, decoded.ID, "Same id")
+	utils.AssertEqual(t, true, cursor.UpdatedAt.Equal(decoded.UpdatedAt), "Same updated at")
+	utils.AssertEqual(t, true, decoded.Backward, "Same direction")
+
+	parts := strings.Split(value, ".")
+	forged := lib.EncodeCursor(lib.Cursor{ID: 8})
+	_, err = lib.DecodeCursor(strings.Split(forged, ".")[0] + "." + parts[1])
+	utils.AssertEqual(t, lib.ErrInvalidCursor, err, "Tampered payload")
+	_, err = lib.DecodeCursor("not-a-cursor")
+	utils.AssertEqual(t, lib.ErrInvalidCursor, err, "Malformed cursor")
+
+	viper.Set("CURSOR_SECRET", "other-secret")
+	_, err = lib.DecodeCursor(value)
+	utils.AssertEqual(t, lib.ErrInvalidCursor, err, "Signed with other key")
+	viper.Set("CURSOR_SECRET", "")
+	utils.AssertEqual(t, lib.ErrCursorSecret, lib.CheckCursorSecret(), "Secret required")
+	_, err = lib.DecodeCursor(lib.EncodeCursor(cursor))
+	utils.AssertEqual(t, lib.ErrInvalidCursor, err, "No cursor without the key")
+	viper.Set("CURSOR_SECRET", "test-cursor-secret")
+	utils.AssertEqual(t, nil, lib.CheckCursorSecret(), "Secret configured")
+}
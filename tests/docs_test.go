@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/controller"
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/docs"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestDocsSpec(t *testing.T) {
+	newTestApp()
+	controller.ConfigureDocs("/api/v1")
+	defer controller.ConfigureDocs("")
+
+	status, result := sendRequest(t, "GET", "/docs/openapi.json", "", "")
+	utils.AssertEqual(t, 200, status, "Openapi document")
+	utils.AssertEqual(t, "3.1.0", result["openapi"], "Openapi version")
+	servers := result["servers"].([]interface{})
+	utils.AssertEqual(t, "/api/v1", servers[0].(map[string]interface{})["url"], "Relative server from ENDPOINT")
+	healthz := result["paths"].(map[string]interface{})["/healthz"].(map[string]interface{})
+	servers = healthz["servers"].([]interface{})
+	utils.AssertEqual(t, "/", servers[0].(map[string]interface{})["url"], "Probe served from the root")
+}
+
+func TestDocsUI(t *testing.T) {
+	response, err := newTestApp().Test(httptest.NewRequest("GET", "/docs", nil), -1)
+	utils.AssertEqual(t, nil, err, "Opening docs")
+	utils.AssertEqual(t, 200, response.StatusCode, "Swagger ui")
+	bte, _ := ioutil.ReadAll(response.Body)
+	utils.AssertEqual(t, true, strings.Contains(string(bte), controller.DocsSpecPath), "Ui load the document")
+
+	response, err = newTestApp().Test(httptest.NewRequest("GET", "/docs/swagger-ui-bundle.js", nil), -1)
+	utils.AssertEqual(t, nil, err, "Loading ui asset")
+	utils.AssertEqual(t, 200, response.StatusCode, "Embedded ui asset")
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	spec, err := lib.ParseOpenAPI(docs.OpenAPI)
+	utils.AssertEqual(t, nil, err, "Parsing openapi document")
+
+	// the middleware is copied to the stack of every method, no route is served on CONNECT
+	stack := newTestApp().Stack()
+	middlewares := map[uintptr]bool{}
+	for _, routes := range stack {
+		for _, route := range routes {
+			if route.Method == fiber.MethodConnect {
+				for _, handler := range route.Handlers {
+					middlewares[reflect.ValueOf(handler).Pointer()] = true
+				}
+			}
+		}
+	}
+
+	for _, routes := range stack {
+		for _, route := range routes {
+			if route.Method == "HEAD" || middlewares[reflect.ValueOf(route.Handlers[0]).Pointer()] {
+				continue
+			}
+			path := route.Path
+			for _, param := range route.Params {
+				path = strings.Replace(path, ":"+param, "1", 1)
+			}
+			operation, _ := spec.Operation(route.Method, path, "")
+			utils.AssertEqual(t, true, nil != operation, route.Method+" "+route.Path+" documented")
+		}
+	}
+}
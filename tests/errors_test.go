@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/jackc/pgconn"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestTranslatePostgresError(t *testing.T) {
+	cases := map[string]error{
+		"23505": services.ErrDuplicate,
+		"23503": services.ErrForeignKeyViolation,
+		"23502": services.ErrNotNullViolation,
+		"23514": services.ErrCheckViolation,
+		"40001": services.ErrSerializationFailure,
+		"08006": services.ErrConnection,
+	}
+	for code, kind := range cases {
+		err := services.TranslateError(fmt.Errorf("create: %w", &pgconn.PgError{Code: code, ConstraintName: "idx_user_email"}))
+		utils.AssertEqual(t, true, errors.Is(err, kind), "Postgres error "+code)
+	}
+
+	err := services.TranslateError(&pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"})
+	utils.AssertEqual(t, "Duplicate data", err.Error(), "Driver message is hidden")
+	httpError, ok := err.(lib.HTTPError)
+	utils.AssertEqual(t, true, ok, "Implements HTTPError")
+	utils.AssertEqual(t, 409, httpError.HTTPStatus(), "Unique violation status")
+	utils.AssertEqual(t, "duplicate", httpError.Code(), "Unique violation code")
+
+	unknown := errors.New("unknown")
+	utils.AssertEqual(t, unknown, services.TranslateError(unknown), "Unknown error")
+	utils.AssertEqual(t, nil, services.TranslateError(nil), "No error")
+	utils.AssertEqual(t, true, errors.Is(services.TranslateError(gorm.ErrRecordNotFound), services.ErrNotFound), "Record not found")
+}
+
+func TestTranslateSqliteError(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=1"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	utils.AssertEqual(t, nil, err, "Opening database")
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(1)
+
+	db.Exec("CREATE TABLE parent (id integer primary key, name text not null unique)")
+	db.Exec("CREATE TABLE child (id integer primary key, parent_id integer references parent(id))")
+	db.Exec("INSERT INTO parent (id, name) VALUES (1, 'a')")
+
+	err = services.TranslateError(db.Exec("INSERT INTO parent (id, name) VALUES (2, 'a')").Error)
+	utils.AssertEqual(t, true, errors.Is(err, services.ErrDuplicate), "Unique violation")
+	err = services.TranslateError(db.Exec("INSERT INTO parent (id, name) VALUES (3, NULL)").Error)
+	utils.AssertEqual(t, true, errors.Is(err, services.ErrNotNullViolation), "Not null violation")
+	err = services.TranslateError(db.Exec("INSERT INTO child (id, parent_id) VALUES (1, 9)").Error)
+	utils.AssertEqual(t, true, errors.Is(err, services.ErrForeignKeyViolation), "Foreign key violation")
+}
@@ -0,0 +1,281 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/controller"
+	"github.com/razanlrahardjo/hacktiv8/app/middleware"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/repository"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/spf13/viper"
+)
+
+// newMemoryApp auth, user, todo, status and trash routes served by the handler with in-memory repositories,
+// the routes after sign up require the access token of memorySignUp
+func newMemoryApp() *fiber.App {
+	viper.Set("JWT_SECRET", "test-secret")
+	handler := controller.NewHandler(repository.NewMemoryRepositories())
+	app := fiber.New()
+	app.Post("/auth/login", handler.Login)
+	app.Post("/auth/refresh", handler.RefreshToken)
+	app.Post("/auth/logout", handler.Logout)
+	app.Post("/users", handler.PostUser)
+	app.Post("/users/:id/restore", middleware.AuthTrashed(handler.Users), handler.RestoreUser)
+	app.Delete("/trash/users/:id", middleware.AuthTrashed(handler.Users), handler.DeleteTrashUser)
+	app.Use(middleware.Auth(handler.Users))
+	app.Get("/users", handler.GetUser)
+	app.Get("/users/:id", handler.GetUserID)
+	app.Post("/status", handler.PostStatus)
+	app.Delete("/status/:id", handler.DeleteStatus)
+	app.Put("/status/:id/transitions", handler.PutStatusTransition)
+	app.Post("/todos", handler.PostTodo)
+	app.Get("/todos", handler.GetTodo)
+	app.Get("/todos/:id", handler.GetTodoID)
+	app.Put("/todos/:id", handler.PutTodo)
+	app.Patch("/todos/:id", handler.PatchTodo)
+	app.Delete("/todos/:id", handler.DeleteTodo)
+	app.Delete("/users/:id", handler.DeleteUser)
+	app.Post("/todos/:id/restore", handler.RestoreTodo)
+	app.Post("/status/:id/restore", handler.RestoreStatus)
+	app.Get("/trash/:resource", handler.GetTrash)
+	app.Delete("/trash/:resource/:id", handler.DeleteTrash)
+	return app
+}
+
+// memorySignUp sign up the user to the memory app and login, return the user and the token pair
+func memorySignUp(t *testing.T, app *fiber.App, name string, email string) (map[string]interface{}, map[string]interface{}) {
+	status, user := sendAppRequest(t, app, "POST", "/users", `{"name":"`+name+`","email":"`+email+`","password":"password123"}`, "")
+	utils.AssertEqual(t, 200, status, "Sign up")
+
+	status, token := sendAppRequest(t, app, "POST", "/auth/login", `{"email":"`+email+`","password":"password123"}`, "")
+	utils.AssertEqual(t, 200, status, "Login")
+
+	return user, token
+}
+
+func TestHandlerTodo(t *testing.T) {
+	app := newMemoryApp()
+	user, pair := memorySignUp(t, app, "Tester", "tester@example.com")
+	token := pair["access_token"].(string)
+	_, open := sendAppRequest(t, app, "POST", "/status", `{"status_text":"Open"}`, token)
+	_, done := sendAppRequest(t, app, "POST", "/status", `{"status_text":"Done","is_terminal":true}`, token)
+	_, review := sendAppRequest(t, app, "POST", "/status", `{"status_text":"Review"}`, token)
+
+	status, _ := sendAppRequest(t, app, "POST", "/users", `{"name":"Tester","email":"Tester@example.com","password":"password123"}`, "")
+	utils.AssertEqual(t, 409, status, "Duplicate email")
+
+	status, todo := sendAppRequest(t, app, "POST", "/todos", fmt.Sprintf(`{"title":"Write","description":"Write test","due_date":"2021-10-10","status_text":"Open","assignee_ids":[%v]}`, user["id"]), token)
+	utils.AssertEqual(t, 200, status, "Creating todo")
+	utils.AssertEqual(t, "Open", todo["status"].(map[string]interface{})["status_text"], "Todo status")
+	utils.AssertEqual(t, 1, len(todo["assignees"].([]interface{})), "Todo assignees")
+
+	status, _ = sendAppRequest(t, app, "POST", "/todos", `{"title":"Write","description":"Write test","due_date":"2021-10-10","status_text":"Open","assignee_ids":[99]}`, token)
+	utils.AssertEqual(t, 400, status, "Assignee not found")
+
+	status, result := sendAppRequest(t, app, "GET", fmt.Sprintf("/todos?status=%v", open["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Listing todo")
+	utils.AssertEqual(t, float64(1), result["total"], "Todo with the status")
+
+	status, _ = sendAppRequest(t, app, "PUT", fmt.Sprintf("/status/%v/transitions", open["id"]), fmt.Sprintf(`{"to_status_ids":[%v]}`, review["id"]), token)
+	utils.AssertEqual(t, 200, status, "Replacing transition")
+	status, result = sendPatchRequest(t, app, controller.MIMEMergePatch, fmt.Sprintf("/todos/%v", todo["id"]), fmt.Sprintf(`{"status_id":%v}`, done["id"]), token)
+	utils.AssertEqual(t, 422, status, "Transition not allowed")
+	utils.AssertEqual(t, 1, len(result["data"].([]interface{})), "Allowed next status")
+
+	status, result = sendPatchRequest(t, app, controller.MIMEMergePatch, fmt.Sprintf("/todos/%v", todo["id"]), fmt.Sprintf(`{"status_id":%v}`, review["id"]), token)
+	utils.AssertEqual(t, 200, status, "Updating todo")
+	utils.AssertEqual(t, "Write", result["title"], "Title kept")
+
+	status, _ = sendAppRequest(t, app, "DELETE", fmt.Sprintf("/status/%v", review["id"]), "", token)
+	utils.AssertEqual(t, 409, status, "Deleting status in use")
+
+	status, _ = sendAppRequest(t, app, "GET", "/todos/99", "", token)
+	utils.AssertEqual(t, 404, status, "Todo not found")
+	status, _ = sendAppRequest(t, app, "GET", "/todos/abc", "", token)
+	utils.AssertEqual(t, 404, status, "Invalid todo id")
+}
+
+func TestHandlerUser(t *testing.T) {
+	app := newMemoryApp()
+	user, pair := memorySignUp(t, app, "Razan", "razan@example.com")
+	token := pair["access_token"].(string)
+	sendAppRequest(t, app, "POST", "/users", `{"name":"Arza","email":"arza@example.com","password":"password123"}`, "")
+	sendAppRequest(t, app, "POST", "/status", `{"status_text":"Open"}`, token)
+	sendAppRequest(t, app, "POST", "/todos", fmt.Sprintf(`{"title":"Write","description":"Write test","due_date":"2021-10-10","status_text":"Open","assignee_ids":[%v]}`, user["id"]), token)
+
+	_, result := sendAppRequest(t, app, "GET", "/users?name=RZ", "", token)
+	utils.AssertEqual(t, 1, len(result["items"].([]interface{})), "Case-insensitive contains")
+	_, result = sendAppRequest(t, app, "GET", "/users?name_prefix=ra", "", token)
+	utils.AssertEqual(t, 1, len(result["items"].([]interface{})), "Prefix match")
+
+	status, result := sendAppRequest(t, app, "GET", fmt.Sprintf("/users/%v?embed=stats,todos", user["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Getting user")
+	utils.AssertEqual(t, 1, len(result["todos"].([]interface{})), "Assigned todos")
+	stats := result["stats"].(map[string]interface{})
+	utils.AssertEqual(t, float64(1), stats["todo_overdue"], "Overdue todo")
+	utils.AssertEqual(t, float64(1), stats["todo_by_status"].(map[string]interface{})["Open"], "Todo by status")
+}
+
+func TestHandlerAuth(t *testing.T) {
+	app := newMemoryApp()
+	_, pair := memorySignUp(t, app, "Razan", "razan@example.com")
+
+	status, _ := sendAppRequest(t, app, "POST", "/auth/login", `{"email":"razan@example.com","password":"wrong-password"}`, "")
+	utils.AssertEqual(t, 401, status, "Wrong password")
+	status, _ = sendAppRequest(t, app, "GET", "/users", "", "")
+	utils.AssertEqual(t, 401, status, "Missing access token")
+
+	status, rotated := sendAppRequest(t, app, "POST", "/auth/refresh", `{"refresh_token":"`+pair["refresh_token"].(string)+`"}`, "")
+	utils.AssertEqual(t, 200, status, "Rotating refresh token")
+	status, _ = sendAppRequest(t, app, "POST", "/auth/refresh", `{"refresh_token":"`+pair["refresh_token"].(string)+`"}`, "")
+	utils.AssertEqual(t, 401, status, "Reusing rotated refresh token")
+
+	status, _ = sendAppRequest(t, app, "POST", "/auth/logout", `{"refresh_token":"`+rotated["refresh_token"].(string)+`"}`, "")
+	utils.AssertEqual(t, 200, status, "Logout")
+	status, _ = sendAppRequest(t, app, "POST", "/auth/refresh", `{"refresh_token":"`+rotated["refresh_token"].(string)+`"}`, "")
+	utils.AssertEqual(t, 401, status, "Refresh after logout")
+}
+
+func TestHandlerTrash(t *testing.T) {
+	app := newMemoryApp()
+	user, pair := memorySignUp(t, app, "Razan", "razan@example.com")
+	token := pair["access_token"].(string)
+	assignee, assigneePair := memorySignUp(t, app, "Arza", "arza@example.com")
+	_, open := sendAppRequest(t, app, "POST", "/status", `{"status_text":"Open"}`, token)
+	_, todo := sendAppRequest(t, app, "POST", "/todos", fmt.Sprintf(`{"title":"Write","description":"Write","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v,%v]}`, open["id"], user["id"], assignee["id"]), token)
+
+	status, _ := sendAppRequest(t, app, "DELETE", fmt.Sprintf("/todos/%v", todo["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Deleting todo")
+	status, _ = sendAppRequest(t, app, "DELETE", fmt.Sprintf("/status/%v", open["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Deleting unused status")
+	status, _ = sendAppRequest(t, app, "DELETE", fmt.Sprintf("/users/%v", assignee["id"]), "", assigneePair["access_token"].(string))
+	utils.AssertEqual(t, 200, status, "Deleting assignee")
+
+	status, result := sendAppRequest(t, app, "GET", "/trash/todos", "", token)
+	utils.AssertEqual(t, 200, status, "Listing trash")
+	utils.AssertEqual(t, true, containsID(result["items"].([]interface{}), todo["id"]), "Deleted todo in the trash")
+	status, _ = sendAppRequest(t, app, "GET", "/trash/unknown", "", token)
+	utils.AssertEqual(t, 404, status, "Unknown trash resource")
+
+	status, _ = sendAppRequest(t, app, "POST", fmt.Sprintf("/todos/%v/restore", todo["id"]), "", token)
+	utils.AssertEqual(t, 409, status, "Status and assignee in the trash")
+	status, _ = sendAppRequest(t, app, "DELETE", fmt.Sprintf("/trash/status/%v", open["id"]), "", token)
+	utils.AssertEqual(t, 409, status, "Purging status of the trashed todo")
+	sendAppRequest(t, app, "POST", fmt.Sprintf("/status/%v/restore", open["id"]), "", token)
+	status, _ = sendAppRequest(t, app, "POST", fmt.Sprintf("/users/%v/restore", assignee["id"]), "", token)
+	utils.AssertEqual(t, 403, status, "Restoring other user")
+	status, _ = sendAppRequest(t, app, "POST", fmt.Sprintf("/users/%v/restore", assignee["id"]), "", assigneePair["access_token"].(string))
+	utils.AssertEqual(t, 200, status, "Restoring own account")
+
+	status, result = sendAppRequest(t, app, "POST", fmt.Sprintf("/todos/%v/restore", todo["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Restoring todo")
+	utils.AssertEqual(t, "Open", result["status"].(map[string]interface{})["status_text"], "Restored with the status")
+	utils.AssertEqual(t, 2, len(result["assignees"].([]interface{})), "Restored with the assignees")
+	status, _ = sendAppRequest(t, app, "POST", fmt.Sprintf("/todos/%v/restore", todo["id"]), "", token)
+	utils.AssertEqual(t, 404, status, "Todo not in the trash")
+
+	sendAppRequest(t, app, "DELETE", fmt.Sprintf("/todos/%v", todo["id"]), "", token)
+	status, _ = sendAppRequest(t, app, "DELETE", fmt.Sprintf("/trash/todos/%v", todo["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Purging todo")
+	_, result = sendAppRequest(t, app, "GET", "/trash/todos", "", token)
+	utils.AssertEqual(t, false, containsID(result["items"].([]interface{}), todo["id"]), "Purged todo")
+}
+
+func TestUpdateVersion(t *testing.T) {
+	newTestApp()
+	for name, statusRepository := range map[string]repository.StatusRepository{
+		"memory": repository.NewMemoryRepositories().Status,
+		"gorm":   repository.NewStatusRepository(services.DB),
+	} {
+		ctx := context.Background()
+		text := "Version"
+		status := model.Status{StatusText: &text}
+		utils.AssertEqual(t, nil, statusRepository.Create(ctx, &status), name+" creating status")
+		utils.AssertEqual(t, 1, status.Version, name+" first version")
+
+		stale := status
+		utils.AssertEqual(t, nil, statusRepository.Update(ctx, &status), name+" updating the current version")
+		utils.AssertEqual(t, 2, status.Version, name+" version incremented")
+		err := statusRepository.Update(ctx, &stale)
+		utils.AssertEqual(t, true, errors.Is(err, services.ErrSerializationFailure), name+" updating the stale version")
+		utils.AssertEqual(t, 1, stale.Version, name+" stale version kept")
+
+		err = statusRepository.Delete(ctx, status.ID, stale.Version, 0)
+		utils.AssertEqual(t, true, errors.Is(err, services.ErrSerializationFailure), name+" deleting the stale version")
+		utils.AssertEqual(t, nil, statusRepository.Delete(ctx, status.ID, status.Version, 0), name+" deleting the current version")
+		_, err = statusRepository.Find(ctx, status.ID)
+		utils.AssertEqual(t, true, errors.Is(err, services.ErrNotFound), name+" status deleted")
+	}
+}
+
+// racingStatus status repository whose record is updated concurrently between the read and the write
+type racingStatus struct {
+	repository.StatusRepository
+}
+
+func (racingStatus) Update(ctx context.Context, status *model.Status) error {
+	return &services.DBError{Kind: services.ErrSerializationFailure}
+}
+
+func (racingStatus) Delete(ctx context.Context, id int, version int, reassignTo int) error {
+	return &services.DBError{Kind: services.ErrSerializationFailure}
+}
+
+func TestConcurrentUpdate(t *testing.T) {
+	repositories := repository.NewMemoryRepositories()
+	repositories.Status = racingStatus{repositories.Status}
+	handler := controller.NewHandler(repositories)
+	app := fiber.New()
+	app.Post("/status", handler.PostStatus)
+	app.Put("/status/:id", handler.PutStatus)
+	app.Delete("/status/:id", handler.DeleteStatus)
+	_, status := sendAppRequest(t, app, "POST", "/status", `{"status_text":"Open"}`, "")
+	url := fmt.Sprintf("/status/%v", status["id"])
+
+	send := func(method string, body string, etag string) int {
+		request := httptest.NewRequest(method, url, strings.NewReader(body))
+		request.Header.Add("Content-Type", "application/json")
+		if etag != "" {
+			request.Header.Add(fiber.HeaderIfMatch, etag)
+		}
+		response, err := app.Test(request, -1)
+		utils.AssertEqual(t, nil, err, "Sending request")
+		return response.StatusCode
+	}
+
+	utils.AssertEqual(t, 412, send("PUT", `{"status_text":"Doing"}`, `"1"`), "Update raced after the If-Match check")
+	utils.AssertEqual(t, 409, send("PUT", `{"status_text":"Doing"}`, ""), "Update raced without If-Match")
+	utils.AssertEqual(t, 412, send("DELETE", "", `"1"`), "Delete raced after the If-Match check")
+}
+
+// unavailableUsers user repository which lost the database connection on FindByIDs
+type unavailableUsers struct {
+	repository.UserRepository
+}
+
+func (unavailableUsers) FindByIDs(ctx context.Context, ids []int) ([]model.User, error) {
+	return nil, &services.DBError{Kind: services.ErrConnection, Err: errors.New("connection refused")}
+}
+
+func TestHandlerDatabaseError(t *testing.T) {
+	repositories := repository.NewMemoryRepositories()
+	repositories.Users = unavailableUsers{repositories.Users}
+	handler := controller.NewHandler(repositories)
+	app := fiber.New()
+	app.Post("/status", handler.PostStatus)
+	app.Post("/todos", handler.PostTodo)
+
+	sendAppRequest(t, app, "POST", "/status", `{"status_text":"Open"}`, "")
+	status, result := sendAppRequest(t, app, "POST", "/todos", `{"title":"Write","description":"Write","due_date":"2021-10-10","status_text":"Open","assignee_ids":[1]}`, "")
+	utils.AssertEqual(t, 503, status, "Assignee lookup failed")
+	utils.AssertEqual(t, "database_unavailable", result["code"], "Connection problem code")
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestHealthz(t *testing.T) {
+	status, result := sendRequest(t, "GET", "/healthz", "", "")
+	utils.AssertEqual(t, 200, status, "Liveness")
+	utils.AssertEqual(t, "up", result["status"], "Process up")
+}
+
+func TestReadyz(t *testing.T) {
+	newTestApp()
+	status, result := sendRequest(t, "GET", "/readyz", "", "")
+	utils.AssertEqual(t, 503, status, "Sweeper not started")
+	checks := result["checks"].(map[string]interface{})
+	utils.AssertEqual(t, "up", checks["database"].(map[string]interface{})["status"], "Database up")
+	utils.AssertEqual(t, "up", checks["migrations"].(map[string]interface{})["status"], "Migrations applied")
+	utils.AssertEqual(t, "down", checks["trash_sweeper"].(map[string]interface{})["status"], "Sweeper down")
+
+	services.StartTrashSweeper()
+	defer services.StopTrashSweeper()
+	status, result = sendRequest(t, "GET", "/readyz", "", "")
+	utils.AssertEqual(t, 200, status, "Ready")
+	utils.AssertEqual(t, "up", result["status"], "Every check up")
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestTranslate(t *testing.T) {
+	utils.AssertEqual(t, "Tidak ditemukan", lib.Translate("id", "Not found"), "Exact message")
+	utils.AssertEqual(t, "Halaman %s tidak valid", lib.Translate("id", "Invalid page %s"), "Message with fmt verbs")
+	utils.AssertEqual(t, "Invalid page 0", lib.Translate("id", "Invalid page 0"), "Formatted message is not matched")
+	utils.AssertEqual(t, "Invalid character 'x'", lib.Translate("id", "Invalid character 'x'"), "Error text is not rewritten")
+	utils.AssertEqual(t, "Not found", lib.Translate("en", "Not found"), "English message")
+	utils.AssertEqual(t, "Not found", lib.Translate("fr", "Not found"), "Unsupported language")
+	utils.AssertEqual(t, "Unknown message", lib.Translate("id", "Unknown message"), "Unknown message")
+}
+
+func TestTranslateArgs(t *testing.T) {
+	app := fiber.New()
+	app.Get("/page", func(c *fiber.Ctx) error {
+		return lib.SendError(c, lib.NewRequestError("Invalid page %s", "0"))
+	})
+	app.Get("/percent", func(c *fiber.Ctx) error {
+		return lib.ErrorBadRequest(c, "Invalid %s 100%")
+	})
+	app.Get("/body", func(c *fiber.Ctx) error {
+		return lib.ErrorBadRequest(c, "Invalid request body %s", "unexpected end of JSON input")
+	})
+
+	for url, detail := range map[string]string{
+		"/page":    "Halaman 0 tidak valid",
+		"/percent": "Invalid %s 100%",
+		"/body":    "Body permintaan tidak valid unexpected end of JSON input",
+	} {
+		request := httptest.NewRequest("GET", url, nil)
+		request.Header.Add("Accept-Language", "id")
+		response, err := app.Test(request)
+		utils.AssertEqual(t, nil, err, "Sending request")
+		utils.AssertEqual(t, 400, response.StatusCode, "Getting status code")
+
+		result := map[string]interface{}{}
+		err = json.NewDecoder(response.Body).Decode(&result)
+		response.Body.Close()
+		utils.AssertEqual(t, nil, err, "Parsing response data")
+		utils.AssertEqual(t, detail, result["detail"], "Translated detail "+url)
+	}
+}
+
+func TestTranslatedResponse(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		return lib.ErrorNotFound(c)
+	})
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Add("Accept-Language", "id-ID, en;q=0.8")
+	response, err := app.Test(request)
+	utils.AssertEqual(t, nil, err, "Sending request")
+	utils.AssertEqual(t, 404, response.StatusCode, "Getting status code")
+
+	defer response.Body.Close()
+	bte, err := ioutil.ReadAll(response.Body)
+	utils.AssertEqual(t, nil, err, "Reading response data")
+
+	var result map[string]interface{}
+	err = json.Unmarshal(bte, &result)
+	utils.AssertEqual(t, nil, err, "Parsing response data")
+	utils.AssertEqual(t, "Tidak ditemukan", result["detail"], "Translated detail")
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/spf13/viper"
+)
+
+// captureLog log entries written while running the function at the log level
+func captureLog(level string, run func()) []map[string]interface{} {
+	output := &bytes.Buffer{}
+	viper.Set("LOG_LEVEL", level)
+	lib.SetLogOutput(output)
+	defer func() {
+		viper.Set("LOG_LEVEL", "")
+		lib.SetLogOutput(os.Stdout)
+	}()
+	run()
+
+	entries := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		entry := map[string]interface{}{}
+		if json.Unmarshal([]byte(line), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestAccessLog(t *testing.T) {
+	userID, token := signUp(t)
+
+	entries := captureLog("debug", func() {
+		request := httptest.NewRequest("GET", "/users", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		request.Header.Set("X-Request-ID", "access-log-test")
+		response, err := newTestApp().Test(request, -1)
+		utils.AssertEqual(t, nil, err, "Sending request")
+		utils.AssertEqual(t, "access-log-test", response.Header.Get("X-Request-ID"), "Incoming request id kept")
+	})
+
+	var access map[string]interface{}
+	queries := 0
+	for _, entry := range entries {
+		utils.AssertEqual(t, "access-log-test", entry["request_id"], "Entry correlated to the request")
+		if entry["msg"] == "request" {
+			access = entry
+		} else if entry["msg"] == "query" {
+			queries++
+		}
+	}
+	utils.AssertEqual(t, true, access != nil, "Access log")
+	utils.AssertEqual(t, "info", access["level"], "Access log level")
+	utils.AssertEqual(t, "GET", access["method"], "Access log method")
+	utils.AssertEqual(t, "/users", access["route"], "Access log route")
+	utils.AssertEqual(t, float64(200), access["status"], "Access log status")
+	utils.AssertEqual(t, strconv.Itoa(int(userID)), access["user"], "Access log user")
+	utils.AssertEqual(t, true, queries > 0, "Query log")
+}
+
+func TestLogLevel(t *testing.T) {
+	entries := captureLog("warn", func() {
+		request := httptest.NewRequest("GET", "/healthz", nil)
+		request.Header.Set("X-Request-ID", "invalid request id\n")
+		response, err := newTestApp().Test(request, -1)
+		utils.AssertEqual(t, nil, err, "Sending request")
+		utils.AssertEqual(t, 36, len(response.Header.Get("X-Request-ID")), "Invalid request id replaced")
+	})
+	utils.AssertEqual(t, 0, len(entries), "Info entries not logged")
+
+	utils.AssertEqual(t, lib.LevelDebug, lib.ParseLogLevel("DEBUG"), "Parsing debug")
+	utils.AssertEqual(t, lib.LevelInfo, lib.ParseLogLevel("verbose"), "Unknown level default to info")
+}
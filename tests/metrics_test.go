@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestMetrics(t *testing.T) {
+	userID, token := signUp(t)
+	_, status := sendRequest(t, "POST", "/status", `{"status_text":"Late"}`, token)
+	code, _ := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Late","description":"Overdue todo","due_date":"2000-01-01","status_id":%v,"assignee_ids":[%v]}`, status["id"], userID), token)
+	utils.AssertEqual(t, 200, code, "Creating todo")
+	sendRequest(t, "GET", "/todos/0", "", token)
+
+	response, err := newTestApp().Test(httptest.NewRequest("GET", "/metrics", nil), -1)
+	utils.AssertEqual(t, nil, err, "Scraping metrics")
+	utils.AssertEqual(t, 200, response.StatusCode, "Metrics status")
+	bte, _ := ioutil.ReadAll(response.Body)
+	body := string(bte)
+
+	for _, metric := range []string{
+		`http_requests_total{method="POST",route="/todos",status="200"}`,
+		`http_requests_total{method="GET",route="/todos/:id",status="404"}`,
+		`http_request_duration_seconds_bucket{method="POST",route="/todos",status="200",le="+Inf"}`,
+		`gorm_query_duration_seconds_count{operation="create",table="todo"}`,
+		`go_sql_open_connections{db_name="main"}`,
+		`todo_total{status="Late"} 1`,
+		`todo_overdue `,
+	} {
+		utils.AssertEqual(t, true, strings.Contains(body, metric), metric)
+	}
+}
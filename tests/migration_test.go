@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/migrations"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMigration(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=1"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	utils.AssertEqual(t, nil, err, "Opening database")
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(1)
+
+	applied, err := migrations.Up(db)
+	utils.AssertEqual(t, nil, err, "Applying migrations")
+	utils.AssertEqual(t, len(migrations.Migrations), len(applied), "Every migration applied")
+	utils.AssertEqual(t, true, db.Migrator().HasTable("todo_assignee"), "Schema created")
+
+	applied, err = migrations.Up(db)
+	utils.AssertEqual(t, nil, err, "Applying migrations again")
+	utils.AssertEqual(t, 0, len(applied), "Nothing pending")
+
+	rolledBack, err := migrations.Down(db, 1)
+	utils.AssertEqual(t, nil, err, "Rolling back")
+	utils.AssertEqual(t, migrations.Migrations[len(migrations.Migrations)-1].Version, rolledBack[0].Version, "Last migration rolled back")
+
+	status, err := migrations.Status(db)
+	utils.AssertEqual(t, nil, err, "Getting status")
+	utils.AssertEqual(t, true, nil != status[0].AppliedAt, "First migration applied")
+	utils.AssertEqual(t, true, nil == status[len(status)-1].AppliedAt, "Last migration pending")
+
+	rolledBack, err = migrations.Down(db, len(migrations.Migrations))
+	utils.AssertEqual(t, nil, err, "Rolling back every migration")
+	utils.AssertEqual(t, len(migrations.Migrations)-1, len(rolledBack), "Every applied migration rolled back")
+	utils.AssertEqual(t, false, db.Migrator().HasTable("todo"), "Schema dropped")
+}
+
+// legacyTodo todo table with the free-form person in charge before the assignee relation
+type legacyTodo struct {
+	ID             int
+	Title          *string
+	PersonInCharge *string `gorm:"type:varchar(256)"`
+}
+
+func (legacyTodo) TableName() string {
+	return "todo"
+}
+
+func TestMigrateTodoAssignee(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=1"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	utils.AssertEqual(t, nil, err, "Opening database")
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+	sqlDB.SetMaxOpenConns(1)
+	_, err = migrations.Up(db)
+	utils.AssertEqual(t, nil, err, "Applying migrations")
+
+	razan, arza := "Razan", "Arza"
+	users := []model.User{{Name: &razan}, {Name: &arza}}
+	utils.AssertEqual(t, nil, db.Create(&users).Error, "Creating users")
+	utils.AssertEqual(t, nil, db.Migrator().AddColumn(&legacyTodo{}, "PersonInCharge"), "Adding legacy column")
+	title, personInCharge := "Write", "Razan, Ghost ,Arza,"
+	todo := legacyTodo{Title: &title, PersonInCharge: &personInCharge}
+	utils.AssertEqual(t, nil, db.Create(&todo).Error, "Creating legacy todo")
+
+	entries := captureLog("warn", func() {
+		err = migrations.MigrateTodoAssignee(db)
+	})
+	utils.AssertEqual(t, nil, err, "Migrating person in charge")
+	utils.AssertEqual(t, 1, len(entries), "Unmatched name logged")
+	utils.AssertEqual(t, "Ghost", entries[0]["person_in_charge"], "Unmatched name")
+
+	assignees := []model.User{}
+	db.Model(&model.Todo{Base: model.Base{ID: todo.ID}}).Association("Assignees").Find(&assignees)
+	utils.AssertEqual(t, 2, len(assignees), "Matched users assigned")
+	var count int64
+	db.Model(&model.User{}).Count(&count)
+	utils.AssertEqual(t, int64(2), count, "No user created for the unmatched name")
+	utils.AssertEqual(t, false, db.Migrator().HasColumn(&legacyTodo{}, "person_in_charge"), "Legacy column dropped")
+
+	utils.AssertEqual(t, nil, migrations.RollbackTodoAssignee(db), "Rolling back")
+	restored := legacyTodo{}
+	db.First(&restored, todo.ID)
+	utils.AssertEqual(t, "Razan, Arza", *restored.PersonInCharge, "Person in charge restored")
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestValidateRequest(t *testing.T) {
+	_, token := signUp(t)
+
+	status, result := sendRequest(t, "GET", "/todos?limit=500&page=x", "", token)
+	utils.AssertEqual(t, 400, status, "Invalid query")
+	utils.AssertEqual(t, "validation_failed", result["code"], "Validation problem code")
+	errors := result["errors"].([]interface{})
+	utils.AssertEqual(t, 2, len(errors), "Every invalid parameter")
+	utils.AssertEqual(t, "invalid_type", errors[0].(map[string]interface{})["code"], "Page not integer")
+	utils.AssertEqual(t, "invalid_value", errors[1].(map[string]interface{})["code"], "Limit above maximum")
+
+	status, _ = sendRequest(t, "GET", "/todos?limit=500&page=x", "", "")
+	utils.AssertEqual(t, 401, status, "Authenticated before validation")
+	status, result = sendRequest(t, "POST", "/users", `{"name":1,"email":"x@example.com","password":"password123"}`, "")
+	utils.AssertEqual(t, 400, status, "Public route validated")
+	utils.AssertEqual(t, "validation_failed", result["code"], "Public route validation problem code")
+
+	status, result = sendRequest(t, "GET", "/todos/abc", "", token)
+	utils.AssertEqual(t, 400, status, "Invalid path parameter")
+	utils.AssertEqual(t, "id", result["errors"].([]interface{})[0].(map[string]interface{})["field"], "Path parameter field")
+
+	status, _ = sendRequest(t, "GET", "/trash/projects", "", token)
+	utils.AssertEqual(t, 400, status, "Resource not in enum")
+
+	status, result = sendRequest(t, "POST", "/todos", `{"title":1,"description":"x","due_date":"2021-10-10","assignee_ids":["a"]}`, token)
+	utils.AssertEqual(t, 400, status, "Invalid body")
+	errors = result["errors"].([]interface{})
+	utils.AssertEqual(t, 2, len(errors), "Every invalid field")
+	utils.AssertEqual(t, "assignee_ids.0", errors[0].(map[string]interface{})["field"], "Array item field")
+	utils.AssertEqual(t, "title", errors[1].(map[string]interface{})["field"], "Wrong type field")
+
+	status, result = sendRequest(t, "POST", "/users", `{"name":`, "")
+	utils.AssertEqual(t, 400, status, "Malformed body")
+	utils.AssertEqual(t, true, strings.HasPrefix(result["detail"].(string), "Invalid request body "), "Parser error as catalog arg")
+
+	status, result = sendRequest(t, "PUT", "/todos/1", "", token)
+	utils.AssertEqual(t, 400, status, "Missing body")
+	utils.AssertEqual(t, "bad_request", result["code"], "Bad request problem code")
+
+	request := httptest.NewRequest("POST", "/todos", strings.NewReader("title=x"))
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Add("Authorization", "Bearer "+token)
+	response, err := newTestApp().Test(request, -1)
+	utils.AssertEqual(t, nil, err, "Sending form")
+	utils.AssertEqual(t, 415, response.StatusCode, "Body must be json")
+}
+
+// schemaDocument openapi document with a schema of every keyword validated
+const schemaDocument = `{
+  "paths": {},
+  "components": {
+    "schemas": {
+      "type": {"type": "integer"},
+      "nullable": {"type": ["string", "null"]},
+      "enum": {"type": "string", "enum": ["todos", "users"]},
+      "minLength": {"type": "string", "minLength": 2},
+      "maxLength": {"type": "string", "maxLength": 3},
+      "pattern": {"type": "string", "pattern": "^#[0-9a-f]{6}$"},
+      "date": {"type": "string", "format": "date"},
+      "email": {"type": "string", "format": "email"},
+      "minimum": {"type": "integer", "minimum": 1},
+      "maximum": {"type": "integer", "maximum": 100},
+      "minItems": {"type": "array", "minItems": 1},
+      "items": {"type": "array", "items": {"type": "integer"}},
+      "required": {"type": "object", "required": ["title"]},
+      "properties": {"type": "object", "properties": {"title": {"type": "string"}}},
+      "allOf": {"allOf": [{"$ref": "#/components/schemas/required"}, {"$ref": "#/components/schemas/properties"}]},
+      "ref": {"$ref": "#/components/schemas/type"}
+    }
+  }
+}`
+
+func TestOpenAPISchema(t *testing.T) {
+	spec, err := lib.ParseOpenAPI([]byte(schemaDocument))
+	utils.AssertEqual(t, nil, err, "Parsing document")
+
+	for _, example := range []struct {
+		schema string
+		value  string
+		codes  []string
+	}{
+		{"type", `1`, []string{}},
+		{"type", `1.5`, []string{lib.CodeInvalidType}},
+		{"type", `"1"`, []string{lib.CodeInvalidType}},
+		{"nullable", `null`, []string{}},
+		{"nullable", `1`, []string{lib.CodeInvalidType}},
+		{"enum", `"users"`, []string{}},
+		{"enum", `"projects"`, []string{lib.CodeInvalidValue}},
+		{"minLength", `"ab"`, []string{}},
+		{"minLength", `"a"`, []string{lib.CodeMinLength}},
+		{"maxLength", `"abc"`, []string{}},
+		{"maxLength", `"abcd"`, []string{lib.CodeMaxLength}},
+		{"pattern", `"#1abc9c"`, []string{}},
+		{"pattern", `"red"`, []string{lib.CodeInvalidFormat}},
+		{"date", `"2021-10-10"`, []string{}},
+		{"date", `"10-10-2021"`, []string{lib.CodeInvalidDate}},
+		{"email", `"razan@example.com"`, []string{}},
+		{"email", `"razan@"`, []string{lib.CodeInvalidEmail}},
+		{"minimum", `1`, []string{}},
+		{"minimum", `0`, []string{lib.CodeInvalidValue}},
+		{"maximum", `100`, []string{}},
+		{"maximum", `101`, []string{lib.CodeInvalidValue}},
+		{"minItems", `[1]`, []string{}},
+		{"minItems", `[]`, []string{lib.CodeMinItems}},
+		{"items", `[1, 2]`, []string{}},
+		{"items", `[1, "a", "b"]`, []string{lib.CodeInvalidType, lib.CodeInvalidType}},
+		{"required", `{"title": "x"}`, []string{}},
+		{"required", `{}`, []string{lib.CodeRequired}},
+		{"properties", `{"title": "x", "other": 1}`, []string{}},
+		{"properties", `{"title": 1}`, []string{lib.CodeInvalidType}},
+		{"allOf", `{"title": "x"}`, []string{}},
+		{"allOf", `{"title": 1}`, []string{lib.CodeInvalidType}},
+		{"allOf", `{}`, []string{lib.CodeRequired}},
+		{"ref", `"1"`, []string{lib.CodeInvalidType}},
+	} {
+		var value interface{}
+		utils.AssertEqual(t, nil, json.Unmarshal([]byte(example.value), &value), "Decoding "+example.value)
+		codes := []string{}
+		for _, err := range spec.Validate(&lib.Schema{Ref: "#/components/schemas/" + example.schema}, value) {
+			codes = append(codes, err.Code)
+		}
+		utils.AssertEqual(t, example.codes, codes, example.schema+" "+example.value)
+	}
+}
+
+func TestOpenAPIParameter(t *testing.T) {
+	spec, err := lib.ParseOpenAPI([]byte(schemaDocument))
+	utils.AssertEqual(t, nil, err, "Parsing document")
+	limit := lib.Parameter{Name: "limit", In: "query", Schema: &lib.Schema{Ref: "#/components/schemas/maximum"}}
+
+	for _, example := range []struct {
+		parameter lib.Parameter
+		value     string
+		present   bool
+		codes     []string
+	}{
+		{limit, "10", true, []string{}},
+		{limit, "x", true, []string{lib.CodeInvalidType}},
+		{limit, "500", true, []string{lib.CodeInvalidValue}},
+		{limit, "", false, []string{}},
+		{lib.Parameter{Name: "id", In: "path", Required: true}, "", false, []string{lib.CodeRequired}},
+	} {
+		codes := []string{}
+		for _, err := range spec.ValidateParameter(example.parameter, example.value, example.present) {
+			codes = append(codes, err.Code)
+		}
+		utils.AssertEqual(t, example.codes, codes, example.parameter.Name+" "+example.value)
+	}
+}
@@ -1,4 +1,4 @@
-package lib
+package tests
 
 import (
 	"encoding/json"
@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
 	"github.com/google/uuid"
@@ -16,7 +18,7 @@ func TestGetXUserID(t *testing.T) {
 	app := fiber.New()
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.Status(200).JSON(fiber.Map{
-			"id": GetXUserID(c),
+			"id": lib.GetXUserID(c),
 		})
 	})
 
@@ -55,7 +57,7 @@ func TestGetLanguage(t *testing.T) {
 	app := fiber.New()
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.Status(200).JSON(fiber.Map{
-			"lang": GetLanguage(c),
+			"lang": lib.GetLanguage(c),
 		})
 	})
 
@@ -89,3 +91,45 @@ func TestGetLanguage(t *testing.T) {
 	utils.AssertEqual(t, nil, err, "Parsing response data")
 	utils.AssertEqual(t, "en", result2["lang"], "same language")
 }
+
+func TestGetActor(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if c.Query("auth") != "" {
+			lib.SetActor(c, c.Query("auth"))
+		}
+		return c.Status(200).JSON(fiber.Map{
+			"actor":   lib.GetActor(c),
+			"context": lib.ActorFromContext(lib.Context(c)),
+		})
+	})
+
+	tests := []struct {
+		url    string
+		header string
+		actor  interface{}
+	}{
+		{"/", "", nil},
+		{"/", "12", "12"},
+		{"/", "not-an-id", nil},
+		{"/?auth=7", "12", "7"},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest("GET", test.url, nil)
+		if test.header != "" {
+			request.Header.Add("X-User-ID", test.header)
+		}
+		response, err := app.Test(request)
+		utils.AssertEqual(t, nil, err, "Getting response data")
+
+		bte, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		utils.AssertEqual(t, nil, err, "Reading response data")
+
+		var result map[string]interface{}
+		err = json.Unmarshal(bte, &result)
+		utils.AssertEqual(t, nil, err, "Parsing response data")
+		utils.AssertEqual(t, test.actor, result["actor"], "actor of "+test.url+" "+test.header)
+		utils.AssertEqual(t, test.actor, result["context"], "context actor of "+test.url+" "+test.header)
+	}
+}
@@ -1,12 +1,18 @@
 //go:build !integration
 // +build !integration
 
-package lib
+package tests
 
 import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
 )
@@ -15,7 +21,7 @@ func TestSend(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		return Send(c, 502, "Bad gateway")
+		return lib.Send(c, 502, "Bad gateway")
 	})
 
 	response, err := app.Test(httptest.NewRequest("GET", "/", nil))
@@ -31,7 +37,7 @@ func TestErrorBadRequest(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		return ErrorBadRequest(c)
+		return lib.ErrorBadRequest(c, "")
 	})
 
 	response, err := app.Test(httptest.NewRequest("GET", "/", nil))
@@ -47,7 +53,7 @@ func TestErrorNotFound(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		return ErrorNotFound(c)
+		return lib.ErrorNotFound(c)
 	})
 
 	response, err := app.Test(httptest.NewRequest("GET", "/", nil))
@@ -63,7 +69,7 @@ func TestErrorConflict(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		return ErrorConflict(c)
+		return lib.ErrorConflict(c)
 	})
 
 	response, err := app.Test(httptest.NewRequest("GET", "/", nil))
@@ -79,7 +85,7 @@ func TestErrorInternal(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		return ErrorInternal(c)
+		return lib.ErrorInternal(c)
 	})
 
 	response, err := app.Test(httptest.NewRequest("GET", "/", nil))
@@ -95,7 +101,7 @@ func TestOK(t *testing.T) {
 	app := fiber.New()
 
 	app.Use(func(c *fiber.Ctx) error {
-		return OK(c)
+		return lib.OK(c)
 	})
 
 	response, err := app.Test(httptest.NewRequest("GET", "/", nil))
@@ -106,3 +112,72 @@ func TestOK(t *testing.T) {
 
 	utils.AssertEqual(t, 200, response.StatusCode, "Example 200 response")
 }
+
+func TestErrorInternalProblem(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(func(c *fiber.Ctx) error {
+		return lib.ErrorInternal(c, errors.New(`pq: relation "todo" does not exist`))
+	})
+
+	request := httptest.NewRequest("GET", "/todos?page=1", nil)
+	request.Header.Add(fiber.HeaderXRequestID, "request-1")
+	response, err := app.Test(request)
+	if nil != err {
+		t.Error(err)
+		return
+	}
+
+	utils.AssertEqual(t, 500, response.StatusCode, "Example 500 response")
+	utils.AssertEqual(t, lib.ProblemContentType, response.Header.Get(fiber.HeaderContentType), "Problem content type")
+
+	problem := lib.Problem{}
+	bte, _ := ioutil.ReadAll(response.Body)
+	utils.AssertEqual(t, nil, json.Unmarshal(bte, &problem), "Parsing problem")
+	utils.AssertEqual(t, lib.Problem{
+		Type:      "/problems/internal_error",
+		Title:     "Internal Server Error",
+		Status:    500,
+		Detail:    "Internal server error",
+		Instance:  "/todos?page=1",
+		Code:      lib.CodeInternal,
+		RequestID: "request-1",
+	}, problem, "Problem without database message")
+}
+
+func TestOKVersion(t *testing.T) {
+	app := fiber.New()
+
+	app.Use(func(c *fiber.Ctx) error {
+		return lib.OKVersion(c, 3, lib.Response{Status: 200})
+	})
+
+	for _, example := range []struct {
+		method      string
+		ifNoneMatch string
+		status      int
+	}{
+		{"GET", "", 200},
+		{"GET", `"3"`, 304},
+		{"GET", `W/"3"`, 304},
+		{"GET", `"1", "2"`, 200},
+		{"GET", "*", 304},
+		{"POST", `"3"`, 200},
+	} {
+		request := httptest.NewRequest(example.method, "/", nil)
+		request.Header.Set("If-None-Match", example.ifNoneMatch)
+		response, err := app.Test(request)
+		utils.AssertEqual(t, nil, err, "Sending request")
+		utils.AssertEqual(t, example.status, response.StatusCode, example.method+" If-None-Match "+example.ifNoneMatch)
+		utils.AssertEqual(t, `"3"`, response.Header.Get("ETag"), "ETag of the version")
+	}
+}
+
+func TestETag(t *testing.T) {
+	utils.AssertEqual(t, `"3"`, lib.ETag(3), "ETag of the version")
+	utils.AssertEqual(t, lib.ETag(3, 1, 2), lib.ETag(3, 1, 2), "Same embedded versions")
+	utils.AssertEqual(t, false, lib.ETag(3, 1, 2) == lib.ETag(3, 1, 3), "Embedded version changed")
+	utils.AssertEqual(t, false, lib.ETag(3, 1, 2) == lib.ETag(3), "Embedded record removed")
+	utils.AssertEqual(t, true, strings.HasPrefix(lib.WeakETag(map[string]int{"a": 1}), `W/"`), "Weak ETag")
+	utils.AssertEqual(t, false, lib.WeakETag(map[string]int{"a": 1}) == lib.WeakETag(map[string]int{"a": 2}), "Representation changed")
+}
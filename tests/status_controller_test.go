@@ -1 +1,120 @@
 package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/controller"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestPostStatus(t *testing.T) {
+	_, token := signUp(t)
+
+	status, result := sendRequest(t, "POST", "/status", `{"status_text":"Review","color":"#1abc9c"}`, token)
+	utils.AssertEqual(t, 200, status, "Creating status")
+	utils.AssertEqual(t, false, result["is_terminal"], "Default not terminal")
+	status, result = sendRequest(t, "POST", "/status", `{"status_text":"Review"}`, token)
+	utils.AssertEqual(t, 409, status, "Duplicate status text")
+	utils.AssertEqual(t, "Duplicate Status", result["detail"], "Duplicate status detail")
+
+	status, result = sendRequest(t, "POST", "/status", `{"status_text":"Too Long Status","color":"green"}`, token)
+	utils.AssertEqual(t, 400, status, "Invalid status")
+	utils.AssertEqual(t, 2, len(result["errors"].([]interface{})), "Every field error")
+}
+
+func TestDeleteStatusInUse(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Queued"}`, token)
+	_, done := sendRequest(t, "POST", "/status", `{"status_text":"Shipped"}`, token)
+	status, _ := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Ship","description":"Ship it","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	utils.AssertEqual(t, 200, status, "Creating todo")
+
+	status, result := sendRequest(t, "DELETE", fmt.Sprintf("/status/%v", open["id"]), "", token)
+	utils.AssertEqual(t, 409, status, "Deleting status in use")
+	utils.AssertEqual(t, "status_in_use", result["code"], "Status in use problem code")
+
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/status/%v?reassign_to=%v", open["id"], done["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Deleting status with reassign")
+}
+
+func TestPatchStatus(t *testing.T) {
+	_, token := signUp(t)
+	_, created := sendRequest(t, "POST", "/status", `{"status_text":"Patch","color":"#1abc9c","position":3}`, token)
+	url := fmt.Sprintf("/status/%v", created["id"])
+
+	status, result := sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, url, `{"color":null}`, token)
+	utils.AssertEqual(t, 200, status, "Merge patch")
+	utils.AssertEqual(t, nil, result["color"], "Null clear the color")
+	utils.AssertEqual(t, float64(3), result["position"], "Position kept")
+
+	status, result = sendRequest(t, "PUT", url, `{"status_text":"Replaced"}`, token)
+	utils.AssertEqual(t, 200, status, "Replacing status")
+	utils.AssertEqual(t, nil, result["position"], "Omitted position cleared")
+	utils.AssertEqual(t, false, result["is_terminal"], "Default not terminal")
+}
+
+func TestTodoStatusReference(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Backlog"}`, token)
+	_, next := sendRequest(t, "POST", "/status", `{"status_text":"Planned"}`, token)
+	body := `{"title":"Plan","description":"Plan","due_date":"2021-10-10",%s,"assignee_ids":[` + fmt.Sprint(userID) + `]}`
+
+	status, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(body, fmt.Sprintf(`"status_id":%v`, open["id"])), token)
+	utils.AssertEqual(t, 200, status, "Status by id")
+	utils.AssertEqual(t, open["id"], todo["status_id"], "Referenced status id")
+	utils.AssertEqual(t, "Backlog", todo["status"].(map[string]interface{})["status_text"], "Referenced status")
+
+	status, result := sendRequest(t, "POST", "/todos", fmt.Sprintf(body, fmt.Sprintf(`"status_id":%v,"status_text":"Planned"`, open["id"])), token)
+	utils.AssertEqual(t, 200, status, "Status by text")
+	utils.AssertEqual(t, next["id"], result["status_id"], "Status text take precedence")
+
+	status, _ = sendRequest(t, "POST", "/todos", fmt.Sprintf(body, `"status_id":999999`), token)
+	utils.AssertEqual(t, 400, status, "Unknown status id")
+	status, _ = sendRequest(t, "POST", "/todos", fmt.Sprintf(body, `"status_text":"Unknown"`), token)
+	utils.AssertEqual(t, 400, status, "Unknown status text")
+
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/status/%v?reassign_to=%v", open["id"], open["id"]), "", token)
+	utils.AssertEqual(t, 400, status, "Reassign to the deleted status")
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/status/%v?reassign_to=%v", open["id"], next["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Deleting status with reassign")
+	_, result = sendRequest(t, "GET", fmt.Sprintf("/todos/%v", todo["id"]), "", token)
+	utils.AssertEqual(t, next["id"], result["status_id"], "Todo reassigned")
+}
+
+func TestStatusTransition(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Opened","position":1}`, token)
+	_, review := sendRequest(t, "POST", "/status", `{"status_text":"Reviewing","position":2}`, token)
+	_, done := sendRequest(t, "POST", "/status", `{"status_text":"Closed","position":3,"is_terminal":true}`, token)
+	_, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Review","description":"Review","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	url := fmt.Sprintf("/todos/%v", todo["id"])
+	body := `{"title":"Review","due_date":"2021-10-10","status_id":%v,"assignee_ids":[` + fmt.Sprint(userID) + `]}`
+
+	status, _ := sendRequest(t, "PUT", fmt.Sprintf("/status/%v/transitions", open["id"]), fmt.Sprintf(`{"to_status_ids":[%v,%v]}`, review["id"], review["id"]), token)
+	utils.AssertEqual(t, 200, status, "Replacing transition")
+	status, _ = sendRequest(t, "PUT", fmt.Sprintf("/status/%v/transitions", open["id"]), `{"to_status_ids":[999999]}`, token)
+	utils.AssertEqual(t, 400, status, "Unknown next status")
+	status, _ = sendRequest(t, "PUT", fmt.Sprintf("/status/%v/transitions", done["id"]), fmt.Sprintf(`{"to_status_ids":[%v]}`, open["id"]), token)
+	utils.AssertEqual(t, 400, status, "Transition from terminal status")
+	status, _ = sendRequest(t, "GET", fmt.Sprintf("/status/%v/transitions", open["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Listing transition")
+
+	status, result := sendRequest(t, "PUT", url, fmt.Sprintf(body, done["id"]), token)
+	utils.AssertEqual(t, 422, status, "Transition not allowed")
+	utils.AssertEqual(t, "invalid_transition", result["code"], "Invalid transition problem code")
+	allowed := result["data"].([]interface{})
+	utils.AssertEqual(t, 1, len(allowed), "Allowed next status")
+	utils.AssertEqual(t, review["id"], allowed[0].(map[string]interface{})["id"], "Allowed transition")
+
+	status, _ = sendRequest(t, "PUT", url, fmt.Sprintf(body, review["id"]), token)
+	utils.AssertEqual(t, 200, status, "Allowed transition")
+	status, _ = sendRequest(t, "PUT", url, fmt.Sprintf(body, done["id"]), token)
+	utils.AssertEqual(t, 200, status, "Any status without transition")
+	status, result = sendRequest(t, "PUT", url, fmt.Sprintf(body, open["id"]), token)
+	utils.AssertEqual(t, 422, status, "Transition from terminal status")
+	utils.AssertEqual(t, 0, len(result["data"].([]interface{})), "Nothing allowed after terminal status")
+	status, _ = sendRequest(t, "PUT", url, fmt.Sprintf(body, done["id"]), token)
+	utils.AssertEqual(t, 200, status, "Same status is not a transition")
+}
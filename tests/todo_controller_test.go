@@ -1,2 +1,219 @@
 package tests
 
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/controller"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestPostTodo(t *testing.T) {
+	userID, token := signUp(t)
+	sendRequest(t, "POST", "/status", `{"status_text":"Todo"}`, token)
+
+	status, result := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Write test","description":"Controller test","due_date":"2021-10-10","status_text":"Todo","assignee_ids":[%v]}`, userID), token)
+	utils.AssertEqual(t, 200, status, "Creating todo")
+	utils.AssertEqual(t, "Todo", result["status"].(map[string]interface{})["status_text"], "Status by text")
+	utils.AssertEqual(t, 1, len(result["assignees"].([]interface{})), "Assignees")
+
+	status, result = sendRequest(t, "GET", fmt.Sprintf("/todos/%v", result["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Getting todo")
+	utils.AssertEqual(t, "Write test", result["title"], "Same title")
+
+	status, result = sendRequest(t, "POST", "/todos", `{"title":"Missing fields"}`, token)
+	utils.AssertEqual(t, 400, status, "Invalid todo")
+	utils.AssertEqual(t, "validation_failed", result["code"], "Validation problem code")
+
+	status, result = sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Undescribed","due_date":"2021-10-10","status_text":"Todo","assignee_ids":[%v]}`, userID), token)
+	utils.AssertEqual(t, 400, status, "Description required on create")
+	utils.AssertEqual(t, "description", result["errors"].([]interface{})[0].(map[string]interface{})["field"], "Description violation")
+
+	status, result = sendRequest(t, "POST", "/todos", `{"title":"x","description":"x","due_date":"2021-10-10","status_text":"Todo","assignee_ids":[999999]}`, token)
+	utils.AssertEqual(t, 400, status, "Unknown assignee")
+}
+
+func TestPutTodoNotFound(t *testing.T) {
+	_, token := signUp(t)
+
+	status, result := sendRequest(t, "PUT", "/todos/999999", `{"title":"Missing","due_date":"2021-10-10","assignee_ids":[1]}`, token)
+	utils.AssertEqual(t, 404, status, "Updating missing todo")
+	utils.AssertEqual(t, "/todos/999999", result["instance"], "Problem instance")
+}
+
+func TestPutTodo(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Put"}`, token)
+	_, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Write","description":"Write test","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	url := fmt.Sprintf("/todos/%v", todo["id"])
+
+	status, result := sendRequest(t, "PUT", url, fmt.Sprintf(`{"title":"Rewrite","due_date":"2021-10-11","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	utils.AssertEqual(t, 200, status, "Replacing todo")
+	utils.AssertEqual(t, nil, result["description"], "Omitted field cleared")
+	utils.AssertEqual(t, todo["created_at"], result["created_at"], "Creation kept")
+
+	status, result = sendRequest(t, "GET", url, "", token)
+	utils.AssertEqual(t, 200, status, "Getting todo")
+	utils.AssertEqual(t, "Rewrite", result["title"], "Title replaced")
+	utils.AssertEqual(t, nil, result["description"], "Description stored cleared")
+
+	status, result = sendRequest(t, "PUT", url, `{"title":"Partial"}`, token)
+	utils.AssertEqual(t, 400, status, "Partial replacement")
+	utils.AssertEqual(t, "validation_failed", result["code"], "Required fields")
+}
+
+func TestPatchTodo(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Patching"}`, token)
+	_, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Write","description":"Write test","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	url := fmt.Sprintf("/todos/%v", todo["id"])
+	app := newTestApp()
+
+	status, result := sendPatchRequest(t, app, controller.MIMEMergePatch, url, `{"title":"Rewrite","description":null}`, token)
+	utils.AssertEqual(t, 200, status, "Merge patch")
+	utils.AssertEqual(t, "Rewrite", result["title"], "Title patched")
+	utils.AssertEqual(t, nil, result["description"], "Null clear the field")
+	utils.AssertEqual(t, true, strings.HasPrefix(result["due_date"].(string), "2021-10-10"), "Other field kept")
+	utils.AssertEqual(t, 1, len(result["assignees"].([]interface{})), "Assignees kept")
+
+	status, result = sendPatchRequest(t, app, controller.MIMEJSONPatch, url, `[{"op":"test","path":"/title","value":"Rewrite"},{"op":"replace","path":"/due_date","value":"2021-12-01"},{"op":"remove","path":"/assignee_ids/0"},{"op":"add","path":"/assignee_ids/-","value":`+fmt.Sprint(userID)+`}]`, token)
+	utils.AssertEqual(t, 200, status, "JSON patch")
+	utils.AssertEqual(t, true, strings.HasPrefix(result["due_date"].(string), "2021-12-01"), "Due date replaced")
+
+	status, _ = sendPatchRequest(t, app, controller.MIMEJSONPatch, url, `[{"op":"test","path":"/title","value":"Write"},{"op":"replace","path":"/title","value":"Lost"}]`, token)
+	utils.AssertEqual(t, 409, status, "Failed test operation")
+	status, _ = sendPatchRequest(t, app, controller.MIMEJSONPatch, url, `[{"op":"replace","path":"/missing/field","value":1}]`, token)
+	utils.AssertEqual(t, 422, status, "Patch not applicable")
+	status, result = sendPatchRequest(t, app, controller.MIMEJSONPatch, url, `[{"op":"remove","path":"/title"}]`, token)
+	utils.AssertEqual(t, 400, status, "Patched todo validated")
+	utils.AssertEqual(t, "title", result["errors"].([]interface{})[0].(map[string]interface{})["field"], "Required title")
+	status, _ = sendPatchRequest(t, app, controller.MIMEJSONPatch, url, `[{"op":"rename","path":"/title"}]`, token)
+	utils.AssertEqual(t, 400, status, "Unknown operation")
+	status, _ = sendPatchRequest(t, app, "application/json", url, `{"title":"Plain"}`, token)
+	utils.AssertEqual(t, 415, status, "Plain json patch")
+
+	status, result = sendRequest(t, "GET", url, "", token)
+	utils.AssertEqual(t, "Rewrite", result["title"], "Rejected patches not stored")
+	utils.AssertEqual(t, true, strings.HasPrefix(result["due_date"].(string), "2021-12-01"), "Applied patch stored")
+}
+
+func TestTodoETag(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"ETag"}`, token)
+	_, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Write","description":"Write","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	url := fmt.Sprintf("/todos/%v", todo["id"])
+	body := fmt.Sprintf(`{"title":"Rewrite","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID)
+
+	status, read := sendConditionalRequest(t, "GET", "application/json", url, "", "If-None-Match", "", token)
+	utils.AssertEqual(t, 200, status, "Getting todo")
+	utils.AssertEqual(t, true, strings.HasPrefix(read, `"1-`), "ETag of the version")
+	status, etag := sendConditionalRequest(t, "GET", "application/json", url, "", "If-None-Match", `W/"0", `+read, token)
+	utils.AssertEqual(t, 304, status, "Not modified")
+	utils.AssertEqual(t, read, etag, "ETag of the cached version")
+
+	sendRequest(t, "PUT", fmt.Sprintf("/status/%v", open["id"]), `{"status_text":"ETag","color":"#1abc9c"}`, token)
+	status, etag = sendConditionalRequest(t, "GET", "application/json", url, "", "If-None-Match", read, token)
+	utils.AssertEqual(t, 200, status, "Embedded status modified since cached")
+	status, _ = sendConditionalRequest(t, "PUT", "application/json", url, body, "If-Match", read, token)
+	utils.AssertEqual(t, 412, status, "Replacing the stale embedded status")
+	read = etag
+
+	status, etag = sendConditionalRequest(t, "PUT", "application/json", url, body, "If-Match", read, token)
+	utils.AssertEqual(t, 200, status, "Replacing the read version")
+	utils.AssertEqual(t, true, strings.HasPrefix(etag, `"2-`), "Version incremented")
+	status, _ = sendConditionalRequest(t, "PUT", "application/json", url, body, "If-Match", read, token)
+	utils.AssertEqual(t, 412, status, "Replacing the stale version")
+	read = etag
+	status, _ = sendConditionalRequest(t, "PATCH", controller.MIMEMergePatch, url, `{"title":"Lost"}`, "If-Match", "W/"+read, token)
+	utils.AssertEqual(t, 412, status, "Weak ETag never match")
+	status, etag = sendConditionalRequest(t, "PATCH", controller.MIMEMergePatch, url, `{"title":"Patched"}`, "If-Match", read, token)
+	utils.AssertEqual(t, 200, status, "Patching the read version")
+	utils.AssertEqual(t, true, strings.HasPrefix(etag, `"3-`), "Version incremented by patch")
+	status, _ = sendConditionalRequest(t, "GET", "application/json", url, "", "If-None-Match", read, token)
+	utils.AssertEqual(t, 200, status, "Modified since cached")
+
+	read = etag
+	sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, fmt.Sprintf("/users/%v", userID), `{"name":"Renamed"}`, token)
+	status, etag = sendConditionalRequest(t, "GET", "application/json", url, "", "If-None-Match", read, token)
+	utils.AssertEqual(t, 200, status, "Embedded assignee modified since cached")
+
+	status, _ = sendConditionalRequest(t, "DELETE", "application/json", url, "", "If-Match", read, token)
+	utils.AssertEqual(t, 412, status, "Deleting the stale version")
+	status, _ = sendConditionalRequest(t, "DELETE", "application/json", url, "", "If-Match", etag, token)
+	utils.AssertEqual(t, 200, status, "Deleting the read version")
+}
+
+func TestGetTodoPagination(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Paged"}`, token)
+	for i, date := range []string{"2021-10-03", "2021-10-01", "2021-10-05", "2021-10-02", "2021-10-04"} {
+		sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Todo %d","description":"Paginate","due_date":"%s","status_id":%v,"assignee_ids":[%v]}`, i, date, open["id"], userID), token)
+	}
+	url := fmt.Sprintf("/todos?status=%v", open["id"])
+
+	status, result := sendRequest(t, "GET", url+"&page=2&limit=2&sort=due_date", "", token)
+	utils.AssertEqual(t, 200, status, "Listing todo")
+	utils.AssertEqual(t, float64(5), result["total"], "Total of the filter")
+	utils.AssertEqual(t, float64(2), result["page"], "Current page")
+	utils.AssertEqual(t, float64(2), result["limit"], "Page limit")
+	utils.AssertEqual(t, float64(3), result["total_pages"], "Total pages")
+	items := result["items"].([]interface{})
+	utils.AssertEqual(t, 2, len(items), "Items of the page")
+	utils.AssertEqual(t, true, strings.HasPrefix(items[0].(map[string]interface{})["due_date"].(string), "2021-10-03"), "Ascending due date")
+
+	_, result = sendRequest(t, "GET", url+"&sort=-due_date,id&limit=1", "", token)
+	items = result["items"].([]interface{})
+	utils.AssertEqual(t, true, strings.HasPrefix(items[0].(map[string]interface{})["due_date"].(string), "2021-10-05"), "Descending due date")
+
+	_, result = sendRequest(t, "GET", url+"&due_date_from=2021-10-02&due_date_to=2021-10-04", "", token)
+	utils.AssertEqual(t, float64(3), result["total"], "Due date range")
+	_, result = sendRequest(t, "GET", fmt.Sprintf("%s&assignee_id=%v", url, userID), "", token)
+	utils.AssertEqual(t, float64(5), result["total"], "Assignee filter")
+	_, result = sendRequest(t, "GET", url+"&page=4&limit=2", "", token)
+	utils.AssertEqual(t, 0, len(result["items"].([]interface{})), "Page beyond the last")
+
+	for _, query := range []string{"&page=0", "&limit=101", "&sort=password", "&due_date_from=10-10-2021", "&assignee_id=x"} {
+		status, _ = sendRequest(t, "GET", url+query, "", token)
+		utils.AssertEqual(t, 400, status, "Invalid query "+query)
+	}
+}
+
+func TestGetTodoCursor(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Cursor"}`, token)
+	for i := 0; i < 5; i++ {
+		sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Todo %d","description":"Paginate","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, i, open["id"], userID), token)
+	}
+	url := fmt.Sprintf("/todos?status=%v&limit=2&cursor=", open["id"])
+
+	ids := []interface{}{}
+	cursors := []string{""}
+	for next := ""; ; {
+		status, result := sendRequest(t, "GET", url+next, "", token)
+		utils.AssertEqual(t, 200, status, "Walking forward")
+		for _, item := range result["items"].([]interface{}) {
+			ids = append(ids, item.(map[string]interface{})["id"])
+		}
+		if result["next_cursor"] == nil {
+			break
+		}
+		next = result["next_cursor"].(string)
+		cursors = append(cursors, next)
+	}
+	utils.AssertEqual(t, 5, len(ids), "Every todo once")
+	utils.AssertEqual(t, 3, len(cursors), "Pages of the limit")
+
+	_, result := sendRequest(t, "GET", url+cursors[2], "", token)
+	utils.AssertEqual(t, 1, len(result["items"].([]interface{})), "Last page")
+	status, result := sendRequest(t, "GET", url+result["prev_cursor"].(string), "", token)
+	utils.AssertEqual(t, 200, status, "Walking backward")
+	items := result["items"].([]interface{})
+	utils.AssertEqual(t, 2, len(items), "Previous page")
+	utils.AssertEqual(t, ids[2], items[0].(map[string]interface{})["id"], "Same order walking backward")
+	utils.AssertEqual(t, ids[3], items[1].(map[string]interface{})["id"], "Same order walking backward")
+
+	status, _ = sendRequest(t, "GET", url+cursors[1]+"x", "", token)
+	utils.AssertEqual(t, 400, status, "Tampered cursor")
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/razanlrahardjo/hacktiv8/app/controller"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+	"github.com/razanlrahardjo/hacktiv8/app/services"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestTrash(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Trash"}`, token)
+	_, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Trash","description":"Trash","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	url := fmt.Sprintf("/todos/%v", todo["id"])
+
+	status, _ := sendRequest(t, "DELETE", url, "", token)
+	utils.AssertEqual(t, 200, status, "Deleting todo")
+	status, _ = sendRequest(t, "GET", url, "", token)
+	utils.AssertEqual(t, 404, status, "Deleted todo hidden")
+	status, result := sendRequest(t, "GET", "/trash/todos?limit=100", "", token)
+	utils.AssertEqual(t, 200, status, "Listing trash")
+	utils.AssertEqual(t, true, containsID(result["items"], todo["id"]), "Deleted todo in the trash")
+	status, _ = sendRequest(t, "GET", "/trash/unknown", "", token)
+	utils.AssertEqual(t, 400, status, "Unknown trash resource")
+
+	status, result = sendRequest(t, "POST", url+"/restore", "", token)
+	utils.AssertEqual(t, 200, status, "Restoring todo")
+	utils.AssertEqual(t, "Trash", result["status"].(map[string]interface{})["status_text"], "Restored with the status")
+	status, _ = sendRequest(t, "POST", url+"/restore", "", token)
+	utils.AssertEqual(t, 404, status, "Restoring todo not in the trash")
+	status, _ = sendRequest(t, "GET", url, "", token)
+	utils.AssertEqual(t, 200, status, "Restored todo visible")
+
+	sendRequest(t, "DELETE", url, "", token)
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/trash/status/%v", open["id"]), "", token)
+	utils.AssertEqual(t, 404, status, "Purging status not in the trash")
+	sendRequest(t, "DELETE", fmt.Sprintf("/status/%v", open["id"]), "", token)
+	status, result = sendRequest(t, "DELETE", fmt.Sprintf("/trash/status/%v", open["id"]), "", token)
+	utils.AssertEqual(t, 409, status, "Purging status of trashed todo")
+	utils.AssertEqual(t, "status_in_use", result["code"], "Status in use problem code")
+
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/trash/todos/%v", todo["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Purging todo")
+	status, _ = sendRequest(t, "POST", url+"/restore", "", token)
+	utils.AssertEqual(t, 404, status, "Purged todo can't be restored")
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/trash/status/%v", open["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Purging unused status")
+}
+
+func TestRestoreTodoDependency(t *testing.T) {
+	userID, token := signUp(t)
+	assigneeID, assigneeToken := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Restore"}`, token)
+	_, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Restore","description":"Restore","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v,%v]}`, open["id"], userID, assigneeID), token)
+	url := fmt.Sprintf("/todos/%v", todo["id"])
+
+	sendRequest(t, "DELETE", url, "", token)
+	status, _ := sendRequest(t, "DELETE", fmt.Sprintf("/status/%v", open["id"]), "", token)
+	utils.AssertEqual(t, 200, status, "Deleting status of trashed todo")
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/users/%v", assigneeID), "", assigneeToken)
+	utils.AssertEqual(t, 200, status, "Deleting assignee")
+
+	status, _ = sendRequest(t, "POST", url+"/restore", "", token)
+	utils.AssertEqual(t, 409, status, "Restoring todo of trashed status and assignee")
+	sendRequest(t, "POST", fmt.Sprintf("/status/%v/restore", open["id"]), "", token)
+	status, _ = sendRequest(t, "POST", url+"/restore", "", token)
+	utils.AssertEqual(t, 409, status, "Restoring todo of trashed assignee")
+	status, _ = sendRequest(t, "POST", fmt.Sprintf("/users/%v/restore", assigneeID), "", token)
+	utils.AssertEqual(t, 403, status, "Restoring other user")
+	status, _ = sendRequest(t, "POST", fmt.Sprintf("/users/%v/restore", assigneeID), "", assigneeToken)
+	utils.AssertEqual(t, 200, status, "Restoring own account")
+	status, result := sendRequest(t, "POST", url+"/restore", "", token)
+	utils.AssertEqual(t, 200, status, "Restoring todo after the dependencies")
+	utils.AssertEqual(t, 2, len(result["assignees"].([]interface{})), "Restored with the assignees")
+}
+
+func TestPurgeUser(t *testing.T) {
+	_, token := signUp(t)
+	userID, userToken := signUp(t)
+	url := fmt.Sprintf("/trash/users/%v", userID)
+
+	sendRequest(t, "DELETE", fmt.Sprintf("/users/%v", userID), "", userToken)
+	status, _ := sendRequest(t, "DELETE", url, "", token)
+	utils.AssertEqual(t, 403, status, "Purging other user")
+	status, _ = sendRequest(t, "GET", "/users", "", userToken)
+	utils.AssertEqual(t, 401, status, "Trashed user access limited to the own account")
+	status, _ = sendRequest(t, "DELETE", url, "", userToken)
+	utils.AssertEqual(t, 200, status, "Purging own account")
+	status, _ = sendRequest(t, "POST", fmt.Sprintf("/users/%v/restore", userID), "", userToken)
+	utils.AssertEqual(t, 401, status, "Purged user can't authenticate")
+}
+
+func TestPatchTrashedAssignee(t *testing.T) {
+	userID, token := signUp(t)
+	assigneeID, assigneeToken := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Assign"}`, token)
+	_, pair := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Pair","description":"Pair","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v,%v]}`, open["id"], userID, assigneeID), token)
+	_, solo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Solo","description":"Solo","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], assigneeID), token)
+	sendRequest(t, "DELETE", fmt.Sprintf("/users/%v", assigneeID), "", assigneeToken)
+
+	status, result := sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, fmt.Sprintf("/todos/%v", pair["id"]), `{"title":"Paired"}`, token)
+	utils.AssertEqual(t, 200, status, "Patching todo of trashed assignee")
+	utils.AssertEqual(t, 1, len(result["assignees"].([]interface{})), "Trashed assignee hidden")
+	status, _ = sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, fmt.Sprintf("/todos/%v", solo["id"]), `{"title":"Alone"}`, token)
+	utils.AssertEqual(t, 200, status, "Patching todo of only trashed assignees")
+
+	sendRequest(t, "POST", fmt.Sprintf("/users/%v/restore", assigneeID), "", assigneeToken)
+	_, result = sendRequest(t, "GET", fmt.Sprintf("/todos/%v", pair["id"]), "", token)
+	utils.AssertEqual(t, 2, len(result["assignees"].([]interface{})), "Assignment kept after the patch")
+	_, result = sendRequest(t, "GET", fmt.Sprintf("/todos/%v", solo["id"]), "", token)
+	utils.AssertEqual(t, 1, len(result["assignees"].([]interface{})), "Only assignee kept after the patch")
+}
+
+func TestTrashSweeper(t *testing.T) {
+	userID, token := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Sweep"}`, token)
+	_, expired := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Expired","description":"Expired","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	_, recent := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Recent","description":"Recent","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], userID), token)
+	sendRequest(t, "DELETE", fmt.Sprintf("/todos/%v", expired["id"]), "", token)
+	sendRequest(t, "DELETE", fmt.Sprintf("/todos/%v", recent["id"]), "", token)
+	services.DB.Unscoped().Model(&model.Todo{}).Where("id = ?", expired["id"]).Update("deleted_at", time.Now().Add(-48*time.Hour))
+
+	sweeper := &services.TrashSweeper{Retention: 24 * time.Hour, Interval: time.Hour}
+	sweeper.Start()
+	utils.AssertEqual(t, true, sweeper.Running(), "Sweeper running")
+	sweeper.Stop()
+	utils.AssertEqual(t, false, sweeper.Running(), "Sweeper stopped")
+
+	_, result := sendRequest(t, "GET", "/trash/todos?limit=100", "", token)
+	utils.AssertEqual(t, false, containsID(result["items"], expired["id"]), "Expired todo purged")
+	utils.AssertEqual(t, true, containsID(result["items"], recent["id"]), "Recent todo kept")
+	var assignees int64
+	services.DB.Table("todo_assignee").Where("todo_id = ?", expired["id"]).Count(&assignees)
+	utils.AssertEqual(t, int64(0), assignees, "Assignees of the expired todo purged")
+}
+
+// containsID check whether the listed items has the id
+func containsID(items interface{}, id interface{}) bool {
+	list, _ := items.([]interface{})
+	for _, item := range list {
+		if item.(map[string]interface{})["id"] == id {
+			return true
+		}
+	}
+	return false
+}
@@ -1 +1,184 @@
 package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/controller"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+func TestPostUser(t *testing.T) {
+	status, result := sendRequest(t, "POST", "/users", `{"name":"Duplicate","email":"duplicate@example.com","password":"password123"}`, "")
+	utils.AssertEqual(t, 200, status, "Creating user")
+	utils.AssertEqual(t, nil, result["password"], "Password is not returned")
+
+	status, result = sendRequest(t, "POST", "/users", `{"name":"Duplicate","email":"Duplicate@example.com","password":"password123"}`, "")
+	utils.AssertEqual(t, 409, status, "Duplicate email")
+	utils.AssertEqual(t, "duplicate", result["code"], "Duplicate problem code")
+
+	status, result = sendRequest(t, "POST", "/users", `{"email":"short@example.com","password":"short"}`, "")
+	utils.AssertEqual(t, 400, status, "Invalid user")
+	utils.AssertEqual(t, 2, len(result["errors"].([]interface{})), "Every field error")
+
+	status, result = sendRequest(t, "POST", "/users", `{"name":"Ghost"}`, "")
+	utils.AssertEqual(t, 400, status, "User without credentials")
+	utils.AssertEqual(t, 2, len(result["errors"].([]interface{})), "Required email and password")
+}
+
+func TestGetUserRequireToken(t *testing.T) {
+	status, result := sendRequest(t, "GET", "/users", "", "")
+	utils.AssertEqual(t, 401, status, "Without token")
+	utils.AssertEqual(t, "unauthorized", result["code"], "Unauthorized problem code")
+
+	_, token := signUp(t)
+	status, result = sendRequest(t, "GET", "/users", "", token)
+	utils.AssertEqual(t, 200, status, "With token")
+	utils.AssertEqual(t, true, len(result["items"].([]interface{})) > 0, "Listing users")
+}
+
+func TestGetUserID(t *testing.T) {
+	userID, token := signUp(t)
+	_, done := sendRequest(t, "POST", "/status", `{"status_text":"Finished","is_terminal":true}`, token)
+	sendRequest(t, "POST", "/status", `{"status_text":"Pending"}`, token)
+	sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Late","description":"Past due","due_date":"2021-10-10","status_text":"Pending","assignee_ids":[%v]}`, userID), token)
+	sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Closed","description":"Done","due_date":"2021-10-11","status_id":%v,"assignee_ids":[%v]}`, done["id"], userID), token)
+
+	status, result := sendRequest(t, "GET", fmt.Sprintf("/users/%v", userID), "", token)
+	utils.AssertEqual(t, 200, status, "Getting user")
+	utils.AssertEqual(t, "Tester", result["name"], "User name")
+	utils.AssertEqual(t, nil, result["todos"], "Todos not embedded")
+
+	status, result = sendRequest(t, "GET", fmt.Sprintf("/users/%v?embed=todos,stats", userID), "", token)
+	utils.AssertEqual(t, 200, status, "Getting user with embeds")
+	todos := result["todos"].([]interface{})
+	utils.AssertEqual(t, 2, len(todos), "Assigned todos")
+	utils.AssertEqual(t, "Late", todos[0].(map[string]interface{})["title"], "Ordered by due date")
+	stats := result["stats"].(map[string]interface{})
+	utils.AssertEqual(t, float64(2), stats["todo_total"], "Total todo")
+	utils.AssertEqual(t, float64(1), stats["todo_done"], "Done todo")
+	utils.AssertEqual(t, float64(1), stats["todo_overdue"], "Overdue todo")
+	utils.AssertEqual(t, float64(1), stats["todo_by_status"].(map[string]interface{})["Pending"], "Todo by status")
+
+	embedURL := fmt.Sprintf("/users/%v?embed=todos,stats", userID)
+	_, etag := sendConditionalRequest(t, "GET", "application/json", embedURL, "", "If-None-Match", "", token)
+	utils.AssertEqual(t, true, strings.HasPrefix(etag, `W/"`), "Weak ETag of the embeds")
+	status, _ = sendConditionalRequest(t, "GET", "application/json", embedURL, "", "If-None-Match", etag, token)
+	utils.AssertEqual(t, 304, status, "Embeds not modified")
+	status, _ = sendConditionalRequest(t, "PUT", "application/json", fmt.Sprintf("/users/%v", userID), `{"name":"Embed","email":"embed@example.com"}`, "If-Match", etag, token)
+	utils.AssertEqual(t, 412, status, "Weak ETag never match")
+
+	status, _ = sendRequest(t, "GET", fmt.Sprintf("/users/%v?embed=projects", userID), "", token)
+	utils.AssertEqual(t, 400, status, "Unknown embed")
+
+	status, _ = sendRequest(t, "GET", "/users/999999", "", token)
+	utils.AssertEqual(t, 404, status, "User not found")
+}
+
+func TestSearchUser(t *testing.T) {
+	_, token := signUp(t)
+	suffix := uuid.New().String()[:8]
+	for _, name := range []string{"Ayu " + suffix, "Bayu " + suffix, "Citra " + suffix} {
+		sendRequest(t, "POST", "/users", fmt.Sprintf(`{"name":"%s","email":"%s@example.com","password":"password123"}`, name, uuid.New().String()), "")
+	}
+
+	status, result := sendRequest(t, "GET", "/users?name=AYU+"+suffix, "", token)
+	utils.AssertEqual(t, 200, status, "Searching user")
+	utils.AssertEqual(t, 2, len(result["items"].([]interface{})), "Case-insensitive contains")
+
+	status, result = sendRequest(t, "GET", "/users?name_prefix=ayu+"+suffix, "", token)
+	utils.AssertEqual(t, 200, status, "Searching user by prefix")
+	items := result["items"].([]interface{})
+	utils.AssertEqual(t, 1, len(items), "Prefix match")
+	utils.AssertEqual(t, "Ayu "+suffix, items[0].(map[string]interface{})["name"], "Prefix matched user")
+
+	status, result = sendRequest(t, "GET", "/users?name=%25", "", token)
+	utils.AssertEqual(t, 200, status, "Searching wildcard")
+	utils.AssertEqual(t, 0, len(result["items"].([]interface{})), "Wildcard is escaped")
+}
+
+func TestPatchUser(t *testing.T) {
+	email := uuid.New().String() + "@example.com"
+	_, user := sendRequest(t, "POST", "/users", `{"name":"Patch","email":"`+email+`","password":"password123"}`, "")
+	_, login := sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`","password":"password123"}`, "")
+	token := login["access_token"].(string)
+	url := fmt.Sprintf("/users/%v", user["id"])
+
+	status, result := sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, url, `{"name":"Patched"}`, token)
+	utils.AssertEqual(t, 200, status, "Merge patch")
+	utils.AssertEqual(t, "Patched", result["name"], "Name patched")
+	utils.AssertEqual(t, email, result["email"], "Email kept")
+	status, _ = sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`","password":"password123"}`, "")
+	utils.AssertEqual(t, 200, status, "Password kept")
+
+	status, result = sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, url, `{"password":"password456"}`, token)
+	utils.AssertEqual(t, 400, status, "Password without the current password")
+	utils.AssertEqual(t, "current_password", result["errors"].([]interface{})[0].(map[string]interface{})["field"], "Required current password")
+	status, _ = sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, url, `{"password":"password456","current_password":"password000"}`, token)
+	utils.AssertEqual(t, 400, status, "Wrong current password")
+	status, result = sendPatchRequest(t, newTestApp(), controller.MIMEJSONPatch, url, `[{"op":"add","path":"/password","value":"password456"},{"op":"add","path":"/current_password","value":"password123"}]`, token)
+	utils.AssertEqual(t, 200, status, "JSON patch")
+	utils.AssertEqual(t, nil, result["current_password"], "Current password not returned")
+	status, _ = sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`","password":"password456"}`, "")
+	utils.AssertEqual(t, 200, status, "Password changed")
+
+	status, result = sendRequest(t, "PUT", url, `{"name":"Replaced"}`, token)
+	utils.AssertEqual(t, 400, status, "Email required on replace")
+	utils.AssertEqual(t, "email", result["errors"].([]interface{})[0].(map[string]interface{})["field"], "Required email")
+	status, result = sendRequest(t, "PUT", url, `{"name":"Replaced","email":"`+email+`"}`, token)
+	utils.AssertEqual(t, 200, status, "Replacing user")
+	utils.AssertEqual(t, "Replaced", result["name"], "Replaced name")
+	status, _ = sendRequest(t, "POST", "/auth/login", `{"email":"`+email+`","password":"password456"}`, "")
+	utils.AssertEqual(t, 200, status, "Password kept by replace")
+}
+
+func TestUserOwnership(t *testing.T) {
+	userID, token := signUp(t)
+	otherID, otherToken := signUp(t)
+	url := fmt.Sprintf("/users/%v", otherID)
+
+	status, result := sendRequest(t, "PUT", url, `{"name":"Taken","email":"taken@example.com"}`, token)
+	utils.AssertEqual(t, 403, status, "Replacing other user")
+	utils.AssertEqual(t, "forbidden", result["code"], "Forbidden problem code")
+	status, _ = sendPatchRequest(t, newTestApp(), controller.MIMEMergePatch, url, `{"name":"Taken"}`, token)
+	utils.AssertEqual(t, 403, status, "Patching other user")
+	status, _ = sendRequest(t, "DELETE", url, "", token)
+	utils.AssertEqual(t, 403, status, "Deleting other user")
+	status, _ = sendRequest(t, "DELETE", "/users/999999", "", token)
+	utils.AssertEqual(t, 403, status, "Deleting unknown user")
+
+	status, result = sendRequest(t, "GET", url, "", otherToken)
+	utils.AssertEqual(t, "Tester", result["name"], "Other user unchanged")
+	status, _ = sendRequest(t, "DELETE", fmt.Sprintf("/users/%v", userID), "", token)
+	utils.AssertEqual(t, 200, status, "Deleting own user")
+	status, _ = sendRequest(t, "GET", url, "", token)
+	utils.AssertEqual(t, 401, status, "Token of deleted user")
+}
+
+func TestGetUserTodo(t *testing.T) {
+	userID, token := signUp(t)
+	otherID, _ := signUp(t)
+	_, open := sendRequest(t, "POST", "/status", `{"status_text":"Assigned"}`, token)
+	status, todo := sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Pair","description":"Pair","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v,%v,%v]}`, open["id"], userID, otherID, userID), token)
+	utils.AssertEqual(t, 200, status, "Creating todo with assignees")
+	assignees := todo["assignees"].([]interface{})
+	utils.AssertEqual(t, 2, len(assignees), "Every assignee once")
+	utils.AssertEqual(t, nil, assignees[0].(map[string]interface{})["password"], "Assignee password hidden")
+	sendRequest(t, "POST", "/todos", fmt.Sprintf(`{"title":"Solo","description":"Solo","due_date":"2021-10-10","status_id":%v,"assignee_ids":[%v]}`, open["id"], otherID), token)
+
+	status, result := sendRequest(t, "GET", fmt.Sprintf("/users/%v/todos", userID), "", token)
+	utils.AssertEqual(t, 200, status, "Listing user todo")
+	items := result["items"].([]interface{})
+	utils.AssertEqual(t, 1, len(items), "Only the assigned todo")
+	utils.AssertEqual(t, "Pair", items[0].(map[string]interface{})["title"], "Assigned todo")
+	_, result = sendRequest(t, "GET", fmt.Sprintf("/users/%v/todos", otherID), "", token)
+	utils.AssertEqual(t, float64(2), result["total"], "Todo of the other assignee")
+	status, _ = sendRequest(t, "GET", fmt.Sprintf("/users/%v/todos?assignee_id=%v", userID, otherID), "", token)
+	utils.AssertEqual(t, 400, status, "Assignee set by the path")
+
+	status, _ = sendRequest(t, "GET", "/users/999999/todos", "", token)
+	utils.AssertEqual(t, 404, status, "Unknown user")
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/razanlrahardjo/hacktiv8/app/lib"
+	"github.com/razanlrahardjo/hacktiv8/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestTodoValidation(t *testing.T) {
+	dueDate := "18-10-2026"
+	todo := model.Todo{DueDate: &dueDate}
+
+	errors := todo.Validation("create")
+	utils.AssertEqual(t, 5, len(errors), "Every violation")
+	utils.AssertEqual(t, lib.FieldError{Field: "title", Code: lib.CodeRequired, Message: "Required %s", Args: []interface{}{"Title"}}, errors[0], "Required field")
+	utils.AssertEqual(t, "description", errors[1].Field, "Required on create")
+	utils.AssertEqual(t, lib.FieldError{Field: "due_date", Code: lib.CodeInvalidDate, Message: "Invalid %s", Args: []interface{}{"Due Date"}}, errors[2], "Invalid date")
+	utils.AssertEqual(t, "assignee_ids", errors[3].Field, "Required slice")
+	utils.AssertEqual(t, "status_id", errors[4].Field, "Required status")
+
+	errors = todo.Validation("replace")
+	utils.AssertEqual(t, 4, len(errors), "Description cleared by replace")
+	utils.AssertEqual(t, "title", errors[0].Field, "Required on replace")
+
+	errors = todo.Validation("update")
+	utils.AssertEqual(t, 1, len(errors), "Required only on create and replace")
+}
+
+func TestUserValidation(t *testing.T) {
+	name, email, password := "Razan", "razan@", "secret"
+	user := model.User{Name: &name, Email: &email, Password: &password}
+
+	errors := user.Validation("create")
+	utils.AssertEqual(t, 2, len(errors), "Every violation")
+	utils.AssertEqual(t, lib.CodeInvalidEmail, errors[0].Code, "Invalid email")
+	utils.AssertEqual(t, lib.FieldError{Field: "password", Code: lib.CodeMinLength, Message: "%s Must Be At Least %d Characters", Args: []interface{}{"Password", 8}}, errors[1], "Minimum length")
+
+	user = model.User{Name: &name}
+	errors = user.Validation("replace")
+	utils.AssertEqual(t, 1, len(errors), "Password kept by replace")
+	utils.AssertEqual(t, "email", errors[0].Field, "Required email")
+}
+
+func TestErrorValidation(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		return lib.ErrorValidation(c, []lib.FieldError{
+			lib.NewFieldError("title", lib.CodeRequired, "Required %s", "Title"),
+		})
+	})
+
+	request := httptest.NewRequest("POST", "/", nil)
+	request.Header.Add("Accept-Language", "id")
+	response, err := app.Test(request)
+	utils.AssertEqual(t, nil, err, "Sending request")
+	utils.AssertEqual(t, 400, response.StatusCode, "Getting status code")
+
+	defer response.Body.Close()
+	bte, err := ioutil.ReadAll(response.Body)
+	utils.AssertEqual(t, nil, err, "Reading response data")
+
+	var result lib.Problem
+	err = json.Unmarshal(bte, &result)
+	utils.AssertEqual(t, nil, err, "Parsing response data")
+	utils.AssertEqual(t, "Validasi gagal", result.Detail, "Translated detail")
+	utils.AssertEqual(t, lib.CodeValidationFailed, result.Code, "Problem code")
+	utils.AssertEqual(t, lib.ProblemContentType, response.Header.Get("Content-Type"), "Problem content type")
+	utils.AssertEqual(t, []lib.FieldError{{Field: "title", Code: lib.CodeRequired, Message: "Judul wajib diisi"}}, result.Errors, "Translated field errors")
+}